@@ -0,0 +1,368 @@
+// Command packcalc calculates pack combinations locally, without a server.
+//
+// Pack sizes are resolved like the server does at startup: the sizes saved
+// in STORAGE_FILE if set, otherwise PACK_SIZES, otherwise the defaults.
+// The --sizes flag overrides all of them. Calculations are bounded by
+// MAX_ORDER_QUANTITY and CALCULATION_MEMORY_BYTES, as on the server.
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"order-pack-calculator/internal/analytics"
+	"order-pack-calculator/internal/calculator"
+	"order-pack-calculator/internal/config"
+	"order-pack-calculator/internal/handler"
+	"order-pack-calculator/internal/model"
+	"order-pack-calculator/internal/storage"
+	"order-pack-calculator/pkg/packing"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const usage = `Usage: packcalc <command> [flags] [arguments]
+
+Commands:
+  calc [flags] QUANTITY...   Calculate packs for one or more order quantities
+  batch [flags] FILE         Calculate packs for a CSV of order_id,quantity rows ("-" reads stdin)
+  analyze [flags]            Summarise excess and pack usage over a range of quantities
+
+Common flags:
+  --sizes 250,500,...        Pack sizes (default: STORAGE_FILE, PACK_SIZES or built-in defaults)
+  --format json|table|csv    Output format (default: table)
+
+Run "packcalc <command> --help" for the flags of a command.
+`
+
+// Exit codes
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// errUsage marks errors caused by invalid command-line arguments
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	// Load .env file if it exists, as the server does
+	config.LoadDotEnv()
+
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes a command and returns the process exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	var err error
+	switch args[0] {
+	case "calc":
+		err = runCalc(args[1:], stdout, stderr)
+	case "batch":
+		err = runBatch(args[1:], stdin, stdout, stderr)
+	case "analyze":
+		err = runAnalyze(args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "packcalc: unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(stderr, "packcalc: %v\n", err)
+		return exitUsage
+	default:
+		fmt.Fprintf(stderr, "packcalc: %v\n", err)
+		return exitError
+	}
+}
+
+// commonFlags are accepted by every command
+type commonFlags struct {
+	sizes  string
+	format string
+}
+
+// newFlagSet creates a flag set with the common flags registered
+func newFlagSet(name string, stderr io.Writer, common *commonFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&common.sizes, "sizes", "", "comma-separated pack sizes")
+	fs.StringVar(&common.format, "format", formatTable, "output format: json, table or csv")
+	return fs
+}
+
+// parseFlags parses arguments, wrapping failures as usage errors
+func parseFlags(fs *flag.FlagSet, args []string, common *commonFlags) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	switch common.format {
+	case formatJSON, formatTable, formatCSV:
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown format %q", errUsage, common.format)
+	}
+}
+
+// runCalc calculates packs for quantities given as arguments
+func runCalc(args []string, stdout, stderr io.Writer) error {
+	var common commonFlags
+	fs := newFlagSet("calc", stderr, &common)
+	if err := parseFlags(fs, args, &common); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("%w: calc needs at least one order quantity", errUsage)
+	}
+
+	sizes, err := resolvePackSizes(common.sizes)
+	if err != nil {
+		return err
+	}
+	solver, err := newSolver(sizes)
+	if err != nil {
+		return err
+	}
+
+	results := make([]model.CalculateResponse, 0, fs.NArg())
+	for _, arg := range fs.Args() {
+		qty, err := parseQuantity(arg)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errUsage, err)
+		}
+		result, err := solve(solver, qty)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+	}
+
+	return writeResults(stdout, common.format, sizes, results)
+}
+
+// runBatch calculates packs for every row of a CSV file
+// Invalid rows are reported on stderr and make the command fail once the
+// remaining rows have been processed.
+func runBatch(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	var common commonFlags
+	fs := newFlagSet("batch", stderr, &common)
+	if err := parseFlags(fs, args, &common); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: batch needs exactly one CSV file", errUsage)
+	}
+
+	sizes, err := resolvePackSizes(common.sizes)
+	if err != nil {
+		return err
+	}
+	solver, err := newSolver(sizes)
+	if err != nil {
+		return err
+	}
+
+	in := stdin
+	if name := fs.Arg(0); name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	reader := csv.NewReader(in)
+	reader.FieldsPerRecord = -1
+
+	results := []model.CalculateResponse{}
+	invalid := 0
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return err
+			}
+			fmt.Fprintf(stderr, "line %d: %v\n", line, err)
+			invalid++
+			continue
+		}
+
+		if len(record) != 2 {
+			fmt.Fprintf(stderr, "line %d: expected 2 fields: order_id,quantity\n", line)
+			invalid++
+			continue
+		}
+
+		qtyField := strings.TrimSpace(record[1])
+		if line == 1 && strings.EqualFold(qtyField, "quantity") {
+			continue // Header row
+		}
+
+		qty, err := parseQuantity(qtyField)
+		if err != nil {
+			fmt.Fprintf(stderr, "line %d: %v\n", line, err)
+			invalid++
+			continue
+		}
+
+		result, err := solve(solver, qty)
+		if err != nil {
+			fmt.Fprintf(stderr, "line %d: %v\n", line, err)
+			invalid++
+			continue
+		}
+		result.ClientOrderID = strings.TrimSpace(record[0])
+		results = append(results, result)
+	}
+
+	if err := writeResults(stdout, common.format, sizes, results); err != nil {
+		return err
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d invalid row(s) skipped", invalid)
+	}
+
+	return nil
+}
+
+// runAnalyze summarises calculations over a range of order quantities
+func runAnalyze(args []string, stdout, stderr io.Writer) error {
+	var common commonFlags
+	fs := newFlagSet("analyze", stderr, &common)
+	from := fs.Int("from", 1, "first order quantity")
+	to := fs.Int("to", 1000, "last order quantity")
+	step := fs.Int("step", 1, "increment between order quantities")
+	if err := parseFlags(fs, args, &common); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%w: analyze takes no arguments", errUsage)
+	}
+	if *from < 0 || *to < *from || *step < 1 {
+		return fmt.Errorf("%w: need 0 <= from <= to and step >= 1", errUsage)
+	}
+
+	sizes, err := resolvePackSizes(common.sizes)
+	if err != nil {
+		return err
+	}
+	solver, err := newSolver(sizes)
+	if err != nil {
+		return err
+	}
+
+	records := []model.OrderRecord{}
+	for qty := *from; qty <= *to; qty += *step {
+		result, err := solve(solver, qty)
+		if err != nil {
+			return err
+		}
+		records = append(records, model.OrderRecord{
+			OrderQuantity: qty,
+			Packs:         result.Packs,
+			TotalItems:    result.TotalItems,
+			TotalPacks:    result.TotalPacks,
+		})
+	}
+
+	return writeAnalysis(stdout, common.format, analysis{
+		PackSizes:        sizes,
+		From:             *from,
+		To:               *to,
+		Step:             *step,
+		AnalyticsSummary: analytics.Totals(records),
+	})
+}
+
+// resolvePackSizes returns the pack sizes to calculate with
+// Follows the server: STORAGE_FILE if it holds pack sizes, else PACK_SIZES
+func resolvePackSizes(flagValue string) ([]int, error) {
+	if flagValue != "" {
+		sizes, err := parseSizesFlag(flagValue)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+		return sizes, nil
+	}
+
+	if storageFile := config.GetEnv("STORAGE_FILE", ""); storageFile != "" {
+		cfg, err := storage.NewStorage(storageFile).Load()
+		if err != nil {
+			return nil, err
+		}
+		if len(cfg.PackSizes) > 0 {
+			return cfg.PackSizes, nil
+		}
+	}
+
+	return config.ParsePackSizes(config.GetEnv("PACK_SIZES", config.DefaultPackSizes)), nil
+}
+
+// newSolver creates a solver for the pack sizes with the server's limits
+func newSolver(sizes []int) (*packing.Solver, error) {
+	solver, err := packing.NewSolver(sizes,
+		packing.WithMaxOrderQuantity(int(config.GetInt("MAX_ORDER_QUANTITY", handler.DefaultMaxOrderQuantity))),
+		packing.WithMemoryBudget(config.GetInt("CALCULATION_MEMORY_BYTES", handler.DefaultMemoryBudget)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pack sizes %v: %w", sizes, err)
+	}
+	return solver, nil
+}
+
+// solve calculates packs for an order quantity
+func solve(solver *packing.Solver, qty int) (model.CalculateResponse, error) {
+	result, err := solver.Solve(qty)
+	if err != nil {
+		return model.CalculateResponse{}, fmt.Errorf("order quantity %d: %w", qty, err)
+	}
+	return calculator.Response(result), nil
+}
+
+// parseSizesFlag strictly parses the --sizes flag
+// Unlike PACK_SIZES, invalid entries are an error rather than skipped.
+func parseSizesFlag(s string) ([]int, error) {
+	sizes := []int{}
+	for _, part := range strings.Split(s, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("invalid pack size %q", part)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
+// parseQuantity parses a non-negative order quantity
+func parseQuantity(s string) (int, error) {
+	qty, err := strconv.Atoi(s)
+	if err != nil || qty < 0 {
+		return 0, fmt.Errorf("invalid order quantity %q", s)
+	}
+	return qty, nil
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"order-pack-calculator/internal/model"
+	"order-pack-calculator/internal/storage"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runCommand(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCalcJSON(t *testing.T) {
+	code, stdout, stderr := runCommand(t, "", "calc", "--sizes", "250,500,1000", "--format", "json", "251", "1001")
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+
+	var results []model.CalculateResponse
+	if err := json.Unmarshal([]byte(stdout), &results); err != nil {
+		t.Fatalf("Failed to decode output: %v", err)
+	}
+	if len(results) != 2 || results[0].TotalItems != 500 || results[1].TotalItems != 1250 {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+}
+
+func TestCalcTable(t *testing.T) {
+	code, stdout, _ := runCommand(t, "", "calc", "--sizes", "250,500", "501")
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d", code)
+	}
+	if !strings.Contains(stdout, "1x500 1x250") || !strings.Contains(stdout, "TOTAL ITEMS") {
+		t.Errorf("Unexpected table output:\n%s", stdout)
+	}
+}
+
+func TestCalcCSV(t *testing.T) {
+	code, stdout, _ := runCommand(t, "", "calc", "--sizes", "250,500", "--format", "csv", "251")
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d", code)
+	}
+
+	want := "order_id,quantity,pack_500,pack_250,total_items,excess,total_packs\n,251,1,0,500,249,1\n"
+	if stdout != want {
+		t.Errorf("Unexpected CSV output:\ngot  %q\nwant %q", stdout, want)
+	}
+}
+
+func TestCalcUsageErrors(t *testing.T) {
+	tests := [][]string{
+		{},
+		{"unknown"},
+		{"calc"},
+		{"calc", "abc"},
+		{"calc", "--sizes", "250,x", "1"},
+		{"calc", "--format", "xml", "1"},
+	}
+
+	for _, args := range tests {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			if code, _, _ := runCommand(t, "", args...); code != exitUsage {
+				t.Errorf("Expected exit code %d, got %d", exitUsage, code)
+			}
+		})
+	}
+}
+
+func TestBatch(t *testing.T) {
+	input := "order_id,quantity\nPO-1,251\nPO-2,oops\nPO-3,12001\n"
+	code, stdout, stderr := runCommand(t, input, "batch", "--format", "json", "-")
+
+	// The invalid row is reported but the rest are still calculated
+	if code != exitError {
+		t.Errorf("Expected exit code %d, got %d", exitError, code)
+	}
+	if !strings.Contains(stderr, "line 3") {
+		t.Errorf("Expected invalid row to be reported, got %q", stderr)
+	}
+
+	var results []model.CalculateResponse
+	if err := json.Unmarshal([]byte(stdout), &results); err != nil {
+		t.Fatalf("Failed to decode output: %v", err)
+	}
+	if len(results) != 2 || results[0].ClientOrderID != "PO-1" || results[1].TotalItems != 12250 {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+}
+
+func TestBatchFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "orders.csv")
+	if err := os.WriteFile(file, []byte("A,1\nB,500\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	code, stdout, stderr := runCommand(t, "", "batch", "--sizes", "250,500", "--format", "csv", file)
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "A,1,0,1,250,249,1") || !strings.Contains(stdout, "B,500,1,0,500,0,1") {
+		t.Errorf("Unexpected CSV output:\n%s", stdout)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	code, stdout, stderr := runCommand(t, "", "analyze", "--sizes", "250,500", "--from", "250", "--to", "500", "--step", "250", "--format", "json")
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+
+	var result analysis
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("Failed to decode output: %v", err)
+	}
+	if result.Orders != 2 || result.ItemsRequested != 750 || result.ExcessItems != 0 {
+		t.Errorf("Unexpected analysis: %+v", result)
+	}
+}
+
+func TestPackSizesFromEnvironment(t *testing.T) {
+	t.Setenv("PACK_SIZES", "23,31,53")
+	t.Setenv("STORAGE_FILE", "")
+
+	code, stdout, _ := runCommand(t, "", "calc", "--format", "json", "263")
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d", code)
+	}
+	if !strings.Contains(stdout, `"total_items": 263`) {
+		t.Errorf("Expected PACK_SIZES to be used, got:\n%s", stdout)
+	}
+
+	// Sizes saved in the storage file take precedence, as in the server
+	file := filepath.Join(t.TempDir(), "pack_sizes.json")
+	if err := storage.NewStorage(file).SavePackSizes([]int{1000}); err != nil {
+		t.Fatalf("Failed to save pack sizes: %v", err)
+	}
+	t.Setenv("STORAGE_FILE", file)
+
+	code, stdout, _ = runCommand(t, "", "calc", "--format", "json", "263")
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d", code)
+	}
+	if !strings.Contains(stdout, `"total_items": 1000`) {
+		t.Errorf("Expected STORAGE_FILE to be used, got:\n%s", stdout)
+	}
+}
+
+func TestCalculationLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       map[string]string
+		args      []string
+		wantError string
+	}{
+		{
+			name:      "default maximum quantity",
+			args:      []string{"calc", "--sizes", "250,500", "2000000000"},
+			wantError: "order quantity exceeds the maximum",
+		},
+		{
+			name:      "MAX_ORDER_QUANTITY",
+			env:       map[string]string{"MAX_ORDER_QUANTITY": "1000"},
+			args:      []string{"calc", "--sizes", "250,500", "1001"},
+			wantError: "order quantity exceeds the maximum",
+		},
+		{
+			name:      "CALCULATION_MEMORY_BYTES",
+			env:       map[string]string{"CALCULATION_MEMORY_BYTES": "1024"},
+			args:      []string{"analyze", "--sizes", "250,500", "--from", "100000", "--to", "100000"},
+			wantError: "order needs more memory than the budget allows",
+		},
+		{
+			name:      "pack size too large",
+			args:      []string{"calc", "--sizes", "250,2000000000", "251"},
+			wantError: "pack size exceeds the maximum",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("STORAGE_FILE", "")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			code, stdout, stderr := runCommand(t, "", tt.args...)
+			if code != exitError {
+				t.Errorf("Expected exit code %d, got %d: %s", exitError, code, stdout)
+			}
+			if !strings.Contains(stderr, tt.wantError) {
+				t.Errorf("Expected error %q, got %q", tt.wantError, stderr)
+			}
+		})
+	}
+}
+
+func TestBatchQuantityTooLarge(t *testing.T) {
+	input := "PO-1,251\nPO-2,2000000000\n"
+	code, stdout, stderr := runCommand(t, input, "batch", "--sizes", "250,500", "--format", "json", "-")
+
+	// The row is reported instead of printing an empty result
+	if code != exitError {
+		t.Errorf("Expected exit code %d, got %d", exitError, code)
+	}
+	if !strings.Contains(stderr, "line 2: order quantity 2000000000") {
+		t.Errorf("Expected the row to be reported, got %q", stderr)
+	}
+
+	var results []model.CalculateResponse
+	if err := json.Unmarshal([]byte(stdout), &results); err != nil {
+		t.Fatalf("Failed to decode output: %v", err)
+	}
+	if len(results) != 1 || results[0].ClientOrderID != "PO-1" {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+}
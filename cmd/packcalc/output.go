@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"order-pack-calculator/internal/calculator"
+	"order-pack-calculator/internal/model"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats
+const (
+	formatJSON  = "json"
+	formatTable = "table"
+	formatCSV   = "csv"
+)
+
+// analysis is the result of the analyze command
+type analysis struct {
+	PackSizes []int `json:"pack_sizes"`
+	From      int   `json:"from"`
+	To        int   `json:"to"`
+	Step      int   `json:"step"`
+	model.AnalyticsSummary
+}
+
+// writeResults prints calculation results in the given format
+// CSV output uses the same columns as the server's CSV endpoint.
+func writeResults(w io.Writer, format string, sizes []int, results []model.CalculateResponse) error {
+	switch format {
+	case formatJSON:
+		return writeJSON(w, results)
+
+	case formatCSV:
+		columns := calculator.UniqueDescending(sizes)
+		header := []string{"order_id", "quantity"}
+		for _, size := range columns {
+			header = append(header, "pack_"+strconv.Itoa(size))
+		}
+		header = append(header, "total_items", "excess", "total_packs")
+
+		writer := csv.NewWriter(w)
+		writer.Write(header)
+		for _, result := range results {
+			counts := packCounts(result.Packs)
+			row := []string{result.ClientOrderID, strconv.Itoa(result.OrderQuantity)}
+			for _, size := range columns {
+				row = append(row, strconv.Itoa(counts[size]))
+			}
+			row = append(row,
+				strconv.Itoa(result.TotalItems),
+				strconv.Itoa(result.TotalItems-result.OrderQuantity),
+				strconv.Itoa(result.TotalPacks),
+			)
+			writer.Write(row)
+		}
+		writer.Flush()
+		return writer.Error()
+
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ORDER ID\tQUANTITY\tPACKS\tTOTAL ITEMS\tEXCESS\tTOTAL PACKS")
+		for _, result := range results {
+			id := result.ClientOrderID
+			if id == "" {
+				id = "-"
+			}
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%d\t%d\t%d\n",
+				id, result.OrderQuantity, formatPacks(result.Packs),
+				result.TotalItems, result.TotalItems-result.OrderQuantity, result.TotalPacks)
+		}
+		return tw.Flush()
+	}
+}
+
+// writeAnalysis prints an analysis in the given format
+func writeAnalysis(w io.Writer, format string, a analysis) error {
+	sizes := calculator.UniqueDescending(a.PackSizes)
+
+	switch format {
+	case formatJSON:
+		return writeJSON(w, a)
+
+	case formatCSV:
+		header := []string{"from", "to", "step", "orders", "items_requested", "items_shipped",
+			"excess_items", "total_packs", "average_excess_percent"}
+		row := []string{
+			strconv.Itoa(a.From), strconv.Itoa(a.To), strconv.Itoa(a.Step),
+			strconv.Itoa(a.Orders), strconv.Itoa(a.ItemsRequested), strconv.Itoa(a.ItemsShipped),
+			strconv.Itoa(a.ExcessItems), strconv.Itoa(a.TotalPacks),
+			strconv.FormatFloat(a.AverageExcessPercent, 'f', 2, 64),
+		}
+		for _, size := range sizes {
+			header = append(header, "pack_"+strconv.Itoa(size))
+			row = append(row, strconv.Itoa(a.PacksBySize[size]))
+		}
+
+		writer := csv.NewWriter(w)
+		writer.Write(header)
+		writer.Write(row)
+		writer.Flush()
+		return writer.Error()
+
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "Pack sizes:\t%s\n", joinInts(a.PackSizes))
+		fmt.Fprintf(tw, "Quantities:\t%d to %d, step %d\n", a.From, a.To, a.Step)
+		fmt.Fprintf(tw, "Orders:\t%d\n", a.Orders)
+		fmt.Fprintf(tw, "Items requested:\t%d\n", a.ItemsRequested)
+		fmt.Fprintf(tw, "Items shipped:\t%d\n", a.ItemsShipped)
+		fmt.Fprintf(tw, "Excess items:\t%d\n", a.ExcessItems)
+		fmt.Fprintf(tw, "Average excess:\t%.2f%%\n", a.AverageExcessPercent)
+		fmt.Fprintf(tw, "Total packs:\t%d\n", a.TotalPacks)
+		for _, size := range sizes {
+			fmt.Fprintf(tw, "Packs of %d:\t%d\n", size, a.PacksBySize[size])
+		}
+		return tw.Flush()
+	}
+}
+
+// writeJSON prints v as indented JSON
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// formatPacks renders a breakdown such as "2x5000 1x250"
+func formatPacks(packs []model.PackBreakdown) string {
+	if len(packs) == 0 {
+		return "-"
+	}
+	parts := make([]string, 0, len(packs))
+	for _, pack := range packs {
+		parts = append(parts, fmt.Sprintf("%dx%d", pack.Quantity, pack.Size))
+	}
+	return strings.Join(parts, " ")
+}
+
+// packCounts indexes a breakdown by pack size
+func packCounts(packs []model.PackBreakdown) map[int]int {
+	counts := make(map[int]int, len(packs))
+	for _, pack := range packs {
+		counts[pack.Size] = pack.Quantity
+	}
+	return counts
+}
+
+// joinInts renders integers as a comma-separated list
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,382 @@
+// Command packctl manages and queries a running order pack calculator server.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"order-pack-calculator/internal/config"
+	"order-pack-calculator/pkg/client"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const usage = `Usage: packctl [global flags] <command> [flags] [arguments]
+
+Commands:
+  packs get                  Show the current pack sizes
+  packs set SIZE[,SIZE...]   Replace the pack sizes
+  calc [flags] QUANTITY      Calculate packs for an order
+  orders list [flags]        List recorded calculations
+  orders get ID              Show a recorded calculation
+
+Global flags:
+  --server URL               Server base URL (default: $PACKCALC_SERVER or http://localhost:8080)
+  --api-key KEY              API key sent as X-API-Key (default: $PACKCALC_API_KEY)
+  --timeout DURATION         Request timeout (default: 30s)
+  --format json|table        Output format (default: table)
+
+Run "packctl <command> --help" for the flags of a command.
+`
+
+// Exit codes
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// Output formats
+const (
+	formatJSON  = "json"
+	formatTable = "table"
+)
+
+// errUsage marks errors caused by invalid command-line arguments
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// cli holds the global options shared by every command
+type cli struct {
+	ctx    context.Context
+	client *client.Client
+	format string
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// run executes a command and returns the process exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	global := flag.NewFlagSet("packctl", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	global.Usage = func() { fmt.Fprint(stderr, usage) }
+	server := global.String("server", config.GetEnv("PACKCALC_SERVER", "http://localhost:8080"), "server base URL")
+	apiKey := global.String("api-key", config.GetEnv("PACKCALC_API_KEY", ""), "API key")
+	timeout := global.Duration("timeout", 30*time.Second, "request timeout")
+	format := global.String("format", formatTable, "output format: json or table")
+
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if *format != formatJSON && *format != formatTable {
+		fmt.Fprintf(stderr, "packctl: unknown format %q\n", *format)
+		return exitUsage
+	}
+
+	apiClient, err := client.New(*server, client.WithAPIKey(*apiKey), client.WithTimeout(*timeout))
+	if err != nil {
+		fmt.Fprintf(stderr, "packctl: %v\n", err)
+		return exitUsage
+	}
+
+	c := &cli{
+		ctx:    context.Background(),
+		client: apiClient,
+		format: *format,
+		stdout: stdout,
+		stderr: stderr,
+	}
+
+	err = c.dispatch(global.Args())
+
+	var apiErr *client.Error
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(stderr, "packctl: %v\n\n%s", err, usage)
+		return exitUsage
+	case errors.As(err, &apiErr):
+		fmt.Fprintf(stderr, "packctl: %s (HTTP %d)\n", apiErr.Response.Error, apiErr.StatusCode)
+		return exitError
+	default:
+		fmt.Fprintf(stderr, "packctl: %v\n", err)
+		return exitError
+	}
+}
+
+// dispatch runs the command named by the first arguments
+func (c *cli) dispatch(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: missing command", errUsage)
+	}
+
+	switch command, rest := args[0], args[1:]; command {
+	case "packs":
+		if len(rest) == 0 {
+			return fmt.Errorf("%w: packs needs a subcommand: get or set", errUsage)
+		}
+		switch rest[0] {
+		case "get":
+			return c.packsGet(rest[1:])
+		case "set":
+			return c.packsSet(rest[1:])
+		}
+		return fmt.Errorf("%w: unknown packs subcommand %q", errUsage, rest[0])
+
+	case "calc":
+		return c.calc(rest)
+
+	case "orders":
+		if len(rest) == 0 {
+			return fmt.Errorf("%w: orders needs a subcommand: list or get", errUsage)
+		}
+		switch rest[0] {
+		case "list":
+			return c.ordersList(rest[1:])
+		case "get":
+			return c.ordersGet(rest[1:])
+		}
+		return fmt.Errorf("%w: unknown orders subcommand %q", errUsage, rest[0])
+
+	case "help":
+		fmt.Fprint(c.stdout, usage)
+		return nil
+	}
+
+	return fmt.Errorf("%w: unknown command %q", errUsage, args[0])
+}
+
+// packsGet prints the current pack sizes
+func (c *cli) packsGet(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("%w: packs get takes no arguments", errUsage)
+	}
+
+	resp, err := c.client.GetPackSizes(c.ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.printPackSizes(resp)
+}
+
+// packsSet replaces the pack sizes
+// Sizes may be given comma-separated, as separate arguments, or both.
+func (c *cli) packsSet(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: packs set needs at least one pack size", errUsage)
+	}
+
+	sizes := []int{}
+	for _, arg := range args {
+		for _, part := range strings.Split(arg, ",") {
+			size, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return fmt.Errorf("%w: invalid pack size %q", errUsage, part)
+			}
+			sizes = append(sizes, size)
+		}
+	}
+
+	// Validation is left to the server so its messages are shown as-is
+	resp, err := c.client.UpdatePackSizes(c.ctx, sizes)
+	if err != nil {
+		return err
+	}
+
+	return c.printPackSizes(resp)
+}
+
+// calc calculates packs for one order
+func (c *cli) calc(args []string) error {
+	fs := c.flagSet("calc")
+	clientOrderID := fs.String("client-order-id", "", "your reference for the order")
+	sku := fs.String("sku", "", "SKU of the ordered item")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: calc needs exactly one order quantity", errUsage)
+	}
+
+	qty, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("%w: invalid order quantity %q", errUsage, fs.Arg(0))
+	}
+
+	req := client.CalculateRequest{OrderQuantity: qty, ClientOrderID: *clientOrderID, SKU: *sku}
+	resp, err := c.client.Calculate(c.ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if c.format == formatJSON {
+		return writeJSON(c.stdout, resp)
+	}
+
+	tw := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PACK SIZE\tQUANTITY")
+	for _, pack := range resp.Packs {
+		fmt.Fprintf(tw, "%d\t%d\n", pack.Size, pack.Quantity)
+	}
+	fmt.Fprintf(tw, "\nTotal items:\t%d\n", resp.TotalItems)
+	fmt.Fprintf(tw, "Excess:\t%d\n", resp.TotalItems-resp.OrderQuantity)
+	fmt.Fprintf(tw, "Total packs:\t%d\n", resp.TotalPacks)
+	if resp.OrderID != "" {
+		fmt.Fprintf(tw, "Order ID:\t%s\n", resp.OrderID)
+	}
+	return tw.Flush()
+}
+
+// ordersList prints a page of recorded calculations
+func (c *cli) ordersList(args []string) error {
+	fs := c.flagSet("orders list")
+	var query client.OrderQuery
+	from := fs.String("from", "", "only orders at or after this RFC 3339 time")
+	to := fs.String("to", "", "only orders before this RFC 3339 time")
+	fs.StringVar(&query.SKU, "sku", "", "only orders for this SKU")
+	version := fs.Int64("version", -1, "only orders calculated with this pack set version")
+	fs.IntVar(&query.Limit, "limit", 0, "maximum number of orders (server default if 0)")
+	fs.IntVar(&query.Offset, "offset", 0, "number of orders to skip")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%w: orders list takes no arguments", errUsage)
+	}
+
+	var err error
+	if query.From, err = parseTimeFlag("from", *from); err != nil {
+		return err
+	}
+	if query.To, err = parseTimeFlag("to", *to); err != nil {
+		return err
+	}
+	if *version >= 0 {
+		query.PackSetVersion = version
+	}
+
+	resp, err := c.client.ListOrders(c.ctx, query)
+	if err != nil {
+		return err
+	}
+
+	if c.format == formatJSON {
+		return writeJSON(c.stdout, resp)
+	}
+
+	tw := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tCREATED\tCLIENT ORDER ID\tSKU\tQUANTITY\tTOTAL ITEMS\tTOTAL PACKS\tVERSION")
+	for _, rec := range resp.Orders {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\n",
+			rec.ID, rec.CreatedAt.Format(time.RFC3339), dash(rec.ClientOrderID), dash(rec.SKU),
+			rec.OrderQuantity, rec.TotalItems, rec.TotalPacks, rec.PackSetVersion)
+	}
+	fmt.Fprintf(tw, "\nShowing %d of %d (offset %d)\n", len(resp.Orders), resp.Total, resp.Offset)
+	return tw.Flush()
+}
+
+// ordersGet prints a single recorded calculation
+func (c *cli) ordersGet(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: orders get needs exactly one order ID", errUsage)
+	}
+
+	rec, err := c.client.GetOrder(c.ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	if c.format == formatJSON {
+		return writeJSON(c.stdout, rec)
+	}
+
+	tw := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "ID:\t%s\n", rec.ID)
+	fmt.Fprintf(tw, "Created:\t%s\n", rec.CreatedAt.Format(time.RFC3339))
+	fmt.Fprintf(tw, "Client order ID:\t%s\n", dash(rec.ClientOrderID))
+	fmt.Fprintf(tw, "SKU:\t%s\n", dash(rec.SKU))
+	fmt.Fprintf(tw, "Order quantity:\t%d\n", rec.OrderQuantity)
+	fmt.Fprintf(tw, "Pack sizes:\t%v (version %d)\n", rec.PackSizes, rec.PackSetVersion)
+	for _, pack := range rec.Packs {
+		fmt.Fprintf(tw, "Packs of %d:\t%d\n", pack.Size, pack.Quantity)
+	}
+	fmt.Fprintf(tw, "Total items:\t%d\n", rec.TotalItems)
+	fmt.Fprintf(tw, "Total packs:\t%d\n", rec.TotalPacks)
+	return tw.Flush()
+}
+
+// printPackSizes prints a pack sizes response
+func (c *cli) printPackSizes(resp *client.PackSizesResponse) error {
+	if c.format == formatJSON {
+		return writeJSON(c.stdout, resp)
+	}
+
+	if resp.Message != "" {
+		fmt.Fprintln(c.stdout, resp.Message)
+	}
+	sizes := make([]string, len(resp.PackSizes))
+	for i, size := range resp.PackSizes {
+		sizes[i] = strconv.Itoa(size)
+	}
+	fmt.Fprintf(c.stdout, "Pack sizes: %s (version %d)\n", strings.Join(sizes, ","), resp.Version)
+	return nil
+}
+
+// flagSet creates a flag set for a command
+func (c *cli) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	return fs
+}
+
+// parseFlags parses arguments, wrapping failures as usage errors
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	return nil
+}
+
+// writeJSON prints v as indented JSON
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// parseTimeFlag parses an optional RFC 3339 time flag
+func parseTimeFlag(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: --%s must be an RFC 3339 time", errUsage, name)
+	}
+	return t, nil
+}
+
+// dash renders empty strings as "-" in tables
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
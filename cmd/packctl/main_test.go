@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"order-pack-calculator/internal/handler"
+	"order-pack-calculator/internal/model"
+	"order-pack-calculator/internal/storage"
+	"strings"
+	"testing"
+)
+
+// newTestServer serves the API backed by a real handler
+// Every request's X-API-Key header is captured in apiKeys.
+func newTestServer(t *testing.T, apiKeys *[]string) *httptest.Server {
+	t.Helper()
+
+	history, err := storage.NewOrderHistory("")
+	if err != nil {
+		t.Fatalf("Failed to create order history: %v", err)
+	}
+	h := handler.NewHandler([]int{250, 500, 1000, 2000, 5000})
+	h.SetOrderHistory(history)
+
+	mux := h.NewRouter()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if apiKeys != nil {
+			*apiKeys = append(*apiKeys, r.Header.Get("X-API-Key"))
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func runCommand(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestPacksGetAndSet(t *testing.T) {
+	var apiKeys []string
+	server := newTestServer(t, &apiKeys)
+
+	code, stdout, stderr := runCommand(t, "--server", server.URL, "--api-key", "secret", "packs", "set", "23,31", "53")
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "Pack sizes: 23,31,53 (version 1)") {
+		t.Errorf("Unexpected output: %q", stdout)
+	}
+
+	code, stdout, _ = runCommand(t, "--server", server.URL, "--api-key", "secret", "--format", "json", "packs", "get")
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d", code)
+	}
+
+	var resp model.PackSizesResponse
+	if err := json.Unmarshal([]byte(stdout), &resp); err != nil {
+		t.Fatalf("Failed to decode output: %v", err)
+	}
+	if len(resp.PackSizes) != 3 || resp.PackSizes[2] != 53 {
+		t.Errorf("Unexpected pack sizes: %v", resp.PackSizes)
+	}
+
+	for _, key := range apiKeys {
+		if key != "secret" {
+			t.Errorf("Expected API key to be sent, got %q", key)
+		}
+	}
+}
+
+func TestServerErrorMessage(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	// The server's ErrorResponse message is shown and the exit code is non-zero
+	code, _, stderr := runCommand(t, "--server", server.URL, "packs", "set", "250,-1")
+	if code != exitError {
+		t.Errorf("Expected exit code %d, got %d", exitError, code)
+	}
+	if !strings.Contains(stderr, "Pack sizes must be positive integers (HTTP 400)") {
+		t.Errorf("Expected server message on stderr, got %q", stderr)
+	}
+
+	code, _, stderr = runCommand(t, "--server", server.URL, "orders", "get", "missing")
+	if code != exitError || !strings.Contains(stderr, "Order not found (HTTP 404)") {
+		t.Errorf("Expected not found error, got %d: %q", code, stderr)
+	}
+}
+
+func TestCalcAndHistory(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	code, stdout, stderr := runCommand(t, "--server", server.URL, "--format", "json", "calc", "--client-order-id", "PO-9", "12001")
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+
+	var calc model.CalculateResponse
+	if err := json.Unmarshal([]byte(stdout), &calc); err != nil {
+		t.Fatalf("Failed to decode output: %v", err)
+	}
+	if calc.TotalItems != 12250 || calc.OrderID == "" {
+		t.Errorf("Unexpected calculation: %+v", calc)
+	}
+
+	code, stdout, _ = runCommand(t, "--server", server.URL, "orders", "list", "--limit", "10")
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d", code)
+	}
+	if !strings.Contains(stdout, calc.OrderID) || !strings.Contains(stdout, "PO-9") {
+		t.Errorf("Expected order in list, got:\n%s", stdout)
+	}
+
+	code, stdout, _ = runCommand(t, "--server", server.URL, "orders", "get", calc.OrderID)
+	if code != exitOK || !strings.Contains(stdout, "Client order ID:  PO-9") || !strings.Contains(stdout, "12250") {
+		t.Errorf("Unexpected order output (%d):\n%s", code, stdout)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	tests := [][]string{
+		{},
+		{"unknown"},
+		{"packs"},
+		{"packs", "set"},
+		{"packs", "set", "abc"},
+		{"calc"},
+		{"calc", "x"},
+		{"orders", "get"},
+		{"--format", "xml", "packs", "get"},
+	}
+
+	for _, args := range tests {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			if code, _, _ := runCommand(t, args...); code != exitUsage {
+				t.Errorf("Expected exit code %d, got %d", exitUsage, code)
+			}
+		})
+	}
+}
+
+func TestConnectionError(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.Close()
+
+	if code, _, _ := runCommand(t, "--server", server.URL, "packs", "get"); code != exitError {
+		t.Errorf("Expected exit code %d, got %d", exitError, code)
+	}
+}
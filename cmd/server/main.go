@@ -1,27 +1,59 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"net"
 	"net/http"
+	"order-pack-calculator/internal/auth"
+	"order-pack-calculator/internal/config"
+	"order-pack-calculator/internal/grpcserver"
 	"order-pack-calculator/internal/handler"
+	"order-pack-calculator/internal/logging"
 	"order-pack-calculator/internal/storage"
+	"order-pack-calculator/internal/webhook"
 	"os"
-	"strconv"
-	"strings"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
 
-	"github.com/joho/godotenv"
+	"google.golang.org/grpc"
 )
 
 func main() {
 	// Load .env file if it exists (optional, won't fail if missing)
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using environment variables or defaults")
+	dotEnvErr := config.LoadDotEnv()
+
+	// Log structured records to stderr, as JSON unless configured otherwise
+	logger, err := logging.New(os.Stderr, config.GetEnv("LOG_LEVEL", "info"), config.GetEnv("LOG_FORMAT", logging.FormatJSON))
+	if err != nil {
+		fatal("Invalid logging configuration", err)
+	}
+	slog.SetDefault(logger)
+	if dotEnvErr != nil {
+		slog.Info("No .env file found, using environment variables or defaults")
 	}
 
 	// Get configuration from environment variables with defaults
-	port := getEnv("PORT", "8080")
-	packSizes := parsePackSizes(getEnv("PACK_SIZES", "250,500,1000,2000,5000"))
-	storageFile := getEnv("STORAGE_FILE", "") // Optional: set to enable persistence
+	port := config.GetEnv("PORT", "8080")
+	grpcPort := config.GetEnv("GRPC_PORT", "") // Optional: set to serve the gRPC API
+	packSizes := config.ParsePackSizes(config.GetEnv("PACK_SIZES", config.DefaultPackSizes))
+	storageFile := config.GetEnv("STORAGE_FILE", "")       // Optional: set to enable persistence
+	historyFile := config.GetEnv("ORDER_HISTORY_FILE", "") // Optional: set to record calculations
+	webhooksFile := config.GetEnv("WEBHOOKS_FILE", "")     // Optional: set to persist webhook subscriptions
+	auditFile := config.GetEnv("AUDIT_LOG_FILE", "")       // Defaults to audit.jsonl next to the storage file
+	cacheEntries := config.GetInt("RESULT_CACHE_ENTRIES", handler.DefaultCacheEntries)
+	cacheBytes := config.GetInt("RESULT_CACHE_BYTES", handler.DefaultCacheBytes)
+	readTimeout := config.GetDuration("HTTP_READ_TIMEOUT", 15*time.Second)
+	writeTimeout := config.GetDuration("HTTP_WRITE_TIMEOUT", 30*time.Second)
+	idleTimeout := config.GetDuration("HTTP_IDLE_TIMEOUT", 120*time.Second)
+	shutdownDelay := config.GetDuration("SHUTDOWN_DELAY", 0)
+	shutdownTimeout := config.GetDuration("SHUTDOWN_TIMEOUT", 20*time.Second)
+
+	// Shut down gracefully on SIGINT (Ctrl+C) and SIGTERM (sent on deploys)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	// Initialize handler with pack sizes
 	var h *handler.Handler
@@ -29,81 +61,200 @@ func main() {
 		// Use persistence layer
 		stor := storage.NewStorage(storageFile)
 		h = handler.NewHandlerWithStorage(packSizes, stor)
-		log.Printf("Persistence enabled: pack sizes will be saved to %s", storageFile)
+		slog.Info("Persistence enabled: pack sizes will be saved to the storage file", "file", storageFile)
+
+		// Pick up changes made to the storage file by other tools
+		pollInterval := config.GetDuration("STORAGE_POLL_INTERVAL", 5*time.Second)
+		if pollInterval > 0 {
+			go h.WatchStorage(ctx, pollInterval)
+			slog.Info("Watching the storage file for changes", "file", storageFile, "interval", pollInterval.String())
+		}
 	} else {
 		// No persistence (in-memory only)
 		h = handler.NewHandler(packSizes)
-		log.Println("Persistence disabled: pack sizes are stored in memory only")
+		slog.Info("Persistence disabled: pack sizes are stored in memory only")
 	}
 
-	// API routes
-	http.HandleFunc("/api/packs", func(w http.ResponseWriter, r *http.Request) {
-		enableCORS(w)
-		if r.Method == http.MethodOptions {
-			return
+	// Record calculation results if requested
+	if historyFile != "" {
+		history, err := storage.NewOrderHistory(historyFile)
+		if err != nil {
+			fatal("Failed to open order history", err)
 		}
-		if r.Method == http.MethodGet {
-			h.GetPackSizes(w, r)
-		} else if r.Method == http.MethodPut {
-			h.UpdatePackSizes(w, r)
-		} else {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		h.SetOrderHistory(history)
+		slog.Info("Order history enabled: calculations will be recorded", "file", historyFile)
+	}
+
+	// Record administrative changes alongside the persisted pack sizes
+	if auditFile == "" && storageFile != "" {
+		auditFile = filepath.Join(filepath.Dir(storageFile), "audit.jsonl")
+	}
+	if auditFile != "" {
+		auditLog, err := storage.NewAuditLog(auditFile)
+		if err != nil {
+			fatal("Failed to open audit log", err)
 		}
+		h.SetAuditLog(auditLog)
+		slog.Info("Audit log enabled: administrative changes will be recorded", "file", auditFile)
+	} else {
+		slog.Info("Audit log is kept in memory only")
+	}
+
+	// Notify webhook subscribers of pack size changes and calculations
+	var webhookStore *storage.WebhookStore
+	if webhooksFile != "" {
+		webhookStore = storage.NewWebhookStore(webhooksFile)
+		slog.Info("Webhook subscriptions will be saved", "file", webhooksFile)
+	}
+	dispatcher, err := webhook.New(webhookStore,
+		webhook.WithRetries(
+			int(config.GetInt("WEBHOOK_MAX_ATTEMPTS", webhook.DefaultMaxAttempts)),
+			config.GetDuration("WEBHOOK_BACKOFF", webhook.DefaultBackoff),
+			webhook.DefaultMaxBackoff,
+		),
+		webhook.WithQueue(
+			int(config.GetInt("WEBHOOK_WORKERS", webhook.DefaultWorkers)),
+			int(config.GetInt("WEBHOOK_QUEUE_SIZE", webhook.DefaultQueueSize)),
+		),
+	)
+	if err != nil {
+		fatal("Failed to load webhooks", err)
+	}
+	h.SetWebhooks(dispatcher)
+
+	// Bound the memory and time a single calculation may use
+	h.SetLimits(handler.Limits{
+		MaxOrderQuantity: int(config.GetInt("MAX_ORDER_QUANTITY", handler.DefaultMaxOrderQuantity)),
+		MemoryBudget:     config.GetInt("CALCULATION_MEMORY_BYTES", handler.DefaultMemoryBudget),
+		Timeout:          config.GetDuration("CALCULATION_TIMEOUT", handler.DefaultCalculationTimeout),
+		MaxCSVRows:       int(config.GetInt("MAX_CSV_ROWS", handler.DefaultMaxCSVRows)),
 	})
 
-	// POST /api/calculate - Calculate optimal pack combination for an order
-	http.HandleFunc("/api/calculate", func(w http.ResponseWriter, r *http.Request) {
-		enableCORS(w)
-		if r.Method == http.MethodOptions {
-			return
+	// Require API keys on every request if any are configured
+	keys, err := auth.ParseKeys(config.GetEnv("API_KEYS", ""))
+	if err != nil {
+		fatal("Invalid API_KEYS", err)
+	}
+	if keysFile := config.GetEnv("API_KEYS_FILE", ""); keysFile != "" {
+		if keys, err = auth.LoadKeys(keysFile, keys); err != nil {
+			fatal("Failed to load API keys", err)
 		}
-		h.CalculatePacks(w, r)
-	})
+	}
+	if keys.Len() > 0 {
+		h.SetAPIKeys(keys)
+		slog.Info("API key authentication enabled", "keys", keys.Len())
+	} else {
+		slog.Warn("No API keys configured, anyone who can reach the server can change pack sizes")
+	}
+
+	// Cache results of repeated order quantities unless disabled
+	if cacheEntries > 0 && cacheBytes > 0 {
+		h.EnableResultCache(int(cacheEntries), cacheBytes)
+		slog.Info("Result cache enabled", "max_entries", cacheEntries, "max_bytes", cacheBytes)
+	}
+
+	// API routes under /api/v1, with the unversioned /api paths kept as
+	// deprecated aliases
+	r := h.NewRouter()
 
 	// Serve static files and frontend
 	fs := http.FileServer(http.Dir("./web"))
-	http.Handle("/", fs)
+	r.Handle("/", fs)
 
-	addr := ":" + port
-	log.Printf("Server starting on http://0.0.0.0%s", addr)
-	log.Printf("Initial pack sizes: %v", packSizes)
+	// Serve the gRPC API on its own port, sharing the handler's state
+	var grpcSrv *grpc.Server
+	grpcErr := make(chan error, 1)
+	if grpcPort != "" {
+		lis, err := net.Listen("tcp", ":"+grpcPort)
+		if err != nil {
+			fatal("Failed to listen for gRPC", err)
+		}
+		grpcSrv = grpc.NewServer(grpcserver.ServerOptions(keys)...)
+		grpcserver.Register(grpcSrv, h)
+		go func() {
+			if err := grpcSrv.Serve(lis); err != nil {
+				grpcErr <- err
+			}
+		}()
+		slog.Info("gRPC server listening", "addr", lis.Addr().String())
+	}
 
-	if err := http.ListenAndServe(addr, nil); err != nil {
-		log.Fatal("Server failed to start: ", err)
+	addr := ":" + port
+	srv := &http.Server{
+		Addr:         addr,
+		Handler:      r,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+		IdleTimeout:  idleTimeout,
 	}
-}
+	slog.Info("Server starting", "url", "http://0.0.0.0"+addr, "pack_sizes", packSizes)
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- srv.ListenAndServe() }()
 
-// getEnv gets environment variable with fallback
-func getEnv(key, fallback string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+	failed := false
+	select {
+	case err := <-serveErr:
+		fatal("Server failed to start", err)
+	case err := <-grpcErr:
+		// Shut down like on a signal so requests in flight finish and
+		// pending writes are flushed, then exit with an error
+		slog.Error("gRPC server failed", "error", err)
+		failed = true
+	case <-ctx.Done():
 	}
-	return fallback
-}
+	stop() // A second signal stops the server immediately
 
-// parsePackSizes parses comma-separated pack sizes
-func parsePackSizes(s string) []int {
-	parts := strings.Split(s, ",")
-	sizes := make([]int, 0, len(parts))
+	// Report not ready first so load balancers stop sending new requests,
+	// then stop accepting connections and wait for requests in flight
+	slog.Info("Shutting down", "delay", shutdownDelay.String(), "timeout", shutdownTimeout.String())
+	h.Drain()
+	time.Sleep(shutdownDelay)
 
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		if num, err := strconv.Atoi(part); err == nil && num > 0 {
-			sizes = append(sizes, num)
-		}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("Drain period ended with requests in flight, closing connections", "error", err)
+		srv.Close()
+	}
+	if grpcSrv != nil {
+		stopGRPC(shutdownCtx, grpcSrv)
 	}
 
-	// Default if parsing failed
-	if len(sizes) == 0 {
-		return []int{250, 500, 1000, 2000, 5000}
+	// Give webhook deliveries the rest of the drain period, then flush
+	// pending writes to disk
+	if err := h.Close(shutdownCtx); err != nil {
+		fatal("Failed to flush storage", err)
+	}
+	if webhookStore != nil {
+		if err := webhookStore.Sync(); err != nil {
+			fatal("Failed to flush webhooks", err)
+		}
 	}
+	slog.Info("Server stopped")
+	if failed {
+		os.Exit(1)
+	}
+}
 
-	return sizes
+// stopGRPC waits for RPCs in flight until ctx is done, then cancels them
+func stopGRPC(ctx context.Context, srv *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		slog.Warn("Drain period ended with RPCs in flight, cancelling them")
+		srv.Stop()
+	}
 }
 
-// enableCORS adds CORS headers
-func enableCORS(w http.ResponseWriter) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+// fatal logs an error that keeps the server from running and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,161 @@
+package analytics
+
+import (
+	"errors"
+	"order-pack-calculator/internal/model"
+	"time"
+)
+
+// Bucket sizes supported by Summarize
+const (
+	Day   = "day"
+	Week  = "week"
+	Month = "month"
+)
+
+// ErrInvalidBucket is returned for an unknown bucket size
+var ErrInvalidBucket = errors.New("bucket must be day, week or month")
+
+// Summarize aggregates recorded orders into totals and a time series
+// Buckets are aligned in UTC and weeks start on Monday. Empty buckets
+// between the first and last order are included so the series has no gaps.
+func Summarize(records []model.OrderRecord, bucket string) (model.AnalyticsResponse, error) {
+	if bucket != Day && bucket != Week && bucket != Month {
+		return model.AnalyticsResponse{}, ErrInvalidBucket
+	}
+
+	var totals accumulator
+	buckets := make(map[time.Time]*accumulator)
+	var first, last time.Time
+
+	for _, rec := range records {
+		totals.add(rec)
+
+		start := bucketStart(rec.CreatedAt, bucket)
+		acc, ok := buckets[start]
+		if !ok {
+			acc = &accumulator{}
+			buckets[start] = acc
+		}
+		acc.add(rec)
+
+		if first.IsZero() || start.Before(first) {
+			first = start
+		}
+		if start.After(last) {
+			last = start
+		}
+	}
+
+	series := []model.AnalyticsBucket{}
+	if len(records) > 0 {
+		for start := first; !start.After(last); start = nextBucket(start, bucket) {
+			acc, ok := buckets[start]
+			if !ok {
+				acc = &accumulator{}
+			}
+			series = append(series, model.AnalyticsBucket{
+				Start:            start,
+				AnalyticsSummary: acc.summary(),
+			})
+		}
+	}
+
+	return model.AnalyticsResponse{
+		Bucket: bucket,
+		Totals: totals.summary(),
+		Series: series,
+	}, nil
+}
+
+// Totals aggregates recorded orders without a time series
+func Totals(records []model.OrderRecord) model.AnalyticsSummary {
+	var acc accumulator
+	for _, rec := range records {
+		acc.add(rec)
+	}
+	return acc.summary()
+}
+
+// accumulator sums up orders for one summary
+type accumulator struct {
+	orders           int
+	itemsRequested   int
+	itemsShipped     int
+	totalPacks       int
+	packsBySize      map[int]int
+	excessPercentSum float64
+	excessPercentN   int // Orders with a positive quantity
+}
+
+// add includes a recorded order
+func (a *accumulator) add(rec model.OrderRecord) {
+	a.orders++
+	a.itemsRequested += rec.OrderQuantity
+	a.itemsShipped += rec.TotalItems
+	a.totalPacks += rec.TotalPacks
+
+	if a.packsBySize == nil {
+		a.packsBySize = make(map[int]int)
+	}
+	for _, pack := range rec.Packs {
+		a.packsBySize[pack.Size] += pack.Quantity
+	}
+
+	// Orders of zero items have no meaningful excess percentage
+	if rec.OrderQuantity > 0 {
+		excess := rec.TotalItems - rec.OrderQuantity
+		a.excessPercentSum += float64(excess) / float64(rec.OrderQuantity) * 100
+		a.excessPercentN++
+	}
+}
+
+// summary returns the accumulated totals
+func (a *accumulator) summary() model.AnalyticsSummary {
+	s := model.AnalyticsSummary{
+		Orders:         a.orders,
+		ItemsRequested: a.itemsRequested,
+		ItemsShipped:   a.itemsShipped,
+		ExcessItems:    a.itemsShipped - a.itemsRequested,
+		TotalPacks:     a.totalPacks,
+		PacksBySize:    make(map[int]int),
+	}
+
+	for size, qty := range a.packsBySize {
+		s.PacksBySize[size] = qty
+	}
+	if a.excessPercentN > 0 {
+		s.AverageExcessPercent = a.excessPercentSum / float64(a.excessPercentN)
+	}
+
+	return s
+}
+
+// bucketStart returns the start of the bucket containing t
+func bucketStart(t time.Time, bucket string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch bucket {
+	case Week:
+		// Go weeks start on Sunday; shift so Monday is day 0
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case Month:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// nextBucket returns the start of the bucket following start
+func nextBucket(start time.Time, bucket string) time.Time {
+	switch bucket {
+	case Week:
+		return start.AddDate(0, 0, 7)
+	case Month:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
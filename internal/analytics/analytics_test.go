@@ -0,0 +1,119 @@
+package analytics
+
+import (
+	"math"
+	"order-pack-calculator/internal/model"
+	"testing"
+	"time"
+)
+
+func TestSummarize(t *testing.T) {
+	// Wednesday 3rd and Thursday 4th January, then Monday 15th
+	records := []model.OrderRecord{
+		{
+			OrderQuantity: 251,
+			Packs:         []model.PackBreakdown{{Size: 500, Quantity: 1}},
+			TotalItems:    500,
+			TotalPacks:    1,
+			CreatedAt:     time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			OrderQuantity: 250,
+			Packs:         []model.PackBreakdown{{Size: 250, Quantity: 1}},
+			TotalItems:    250,
+			TotalPacks:    1,
+			CreatedAt:     time.Date(2024, 1, 4, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			OrderQuantity: 501,
+			Packs:         []model.PackBreakdown{{Size: 500, Quantity: 1}, {Size: 250, Quantity: 1}},
+			TotalItems:    750,
+			TotalPacks:    2,
+			CreatedAt:     time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC),
+		},
+	}
+
+	got, err := Summarize(records, Week)
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+
+	totals := got.Totals
+	if totals.Orders != 3 || totals.ItemsRequested != 1002 || totals.ItemsShipped != 1500 {
+		t.Errorf("Unexpected totals: %+v", totals)
+	}
+	if totals.ExcessItems != 498 || totals.TotalPacks != 4 {
+		t.Errorf("Unexpected excess or pack totals: %+v", totals)
+	}
+	if totals.PacksBySize[500] != 2 || totals.PacksBySize[250] != 2 {
+		t.Errorf("Unexpected packs by size: %v", totals.PacksBySize)
+	}
+
+	// (249/251 + 0 + 249/501) / 3 * 100
+	wantPercent := (249.0/251 + 249.0/501) / 3 * 100
+	if math.Abs(totals.AverageExcessPercent-wantPercent) > 1e-9 {
+		t.Errorf("AverageExcessPercent = %v, want %v", totals.AverageExcessPercent, wantPercent)
+	}
+
+	// Weeks of 1st, 8th (empty) and 15th January
+	if len(got.Series) != 3 {
+		t.Fatalf("Expected 3 weekly buckets, got %d", len(got.Series))
+	}
+	wantStarts := []time.Time{
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+	}
+	wantOrders := []int{2, 0, 1}
+	for i, bucket := range got.Series {
+		if !bucket.Start.Equal(wantStarts[i]) {
+			t.Errorf("Bucket %d start = %v, want %v", i, bucket.Start, wantStarts[i])
+		}
+		if bucket.Orders != wantOrders[i] {
+			t.Errorf("Bucket %d orders = %d, want %d", i, bucket.Orders, wantOrders[i])
+		}
+	}
+}
+
+func TestSummarizeBuckets(t *testing.T) {
+	records := []model.OrderRecord{
+		{OrderQuantity: 1, TotalItems: 1, CreatedAt: time.Date(2024, 1, 31, 23, 0, 0, 0, time.UTC)},
+		{OrderQuantity: 1, TotalItems: 1, CreatedAt: time.Date(2024, 3, 1, 1, 0, 0, 0, time.UTC)},
+	}
+
+	tests := []struct {
+		bucket     string
+		wantSeries int
+	}{
+		{bucket: Day, wantSeries: 31},
+		{bucket: Month, wantSeries: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.bucket, func(t *testing.T) {
+			got, err := Summarize(records, tt.bucket)
+			if err != nil {
+				t.Fatalf("Summarize() error = %v", err)
+			}
+			if len(got.Series) != tt.wantSeries {
+				t.Errorf("Summarize() series length = %d, want %d", len(got.Series), tt.wantSeries)
+			}
+		})
+	}
+}
+
+func TestSummarizeEmpty(t *testing.T) {
+	got, err := Summarize(nil, Day)
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if got.Totals.Orders != 0 || len(got.Series) != 0 {
+		t.Errorf("Expected empty summary, got %+v", got)
+	}
+}
+
+func TestSummarizeInvalidBucket(t *testing.T) {
+	if _, err := Summarize(nil, "year"); err != ErrInvalidBucket {
+		t.Errorf("Summarize() error = %v, want ErrInvalidBucket", err)
+	}
+}
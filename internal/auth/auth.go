@@ -0,0 +1,147 @@
+// Package auth authenticates API clients by key
+//
+// Keys are configured by their SHA-256 hash, never in plain text, together
+// with a name identifying the client and a role. Entries have the form
+//
+//	name:role:sha256-hex
+//
+// e.g. "erp:admin:5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8".
+// The hash of a key can be computed with `printf '%s' "$KEY" | sha256sum`.
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Role decides what a key may do
+type Role string
+
+const (
+	// RoleRead may calculate packs and view configuration and results
+	RoleRead Role = "read"
+
+	// RoleAdmin may also change pack sizes and manage webhooks
+	RoleAdmin Role = "admin"
+)
+
+// Allows reports whether the role grants what required does
+func (r Role) Allows(required Role) bool {
+	return r == RoleAdmin || r == required
+}
+
+// Key is an authenticated client
+type Key struct {
+	Name string
+	Role Role
+}
+
+// Keys holds the configured keys by hash
+// A nil or empty Keys has no keys; callers treat that as authentication
+// being disabled.
+type Keys struct {
+	byHash map[string]Key
+}
+
+// HashKey returns the hex SHA-256 hash of a key as used in entries
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseKeys parses key entries separated by commas or newlines
+// Blank entries and lines starting with # are ignored.
+func ParseKeys(spec string) (*Keys, error) {
+	keys := &Keys{byHash: make(map[string]Key)}
+	if err := keys.add(spec); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// LoadKeys parses the key entries of a file and adds them to extra, which
+// may be nil
+func LoadKeys(filename string, extra *Keys) (*Keys, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys file: %w", err)
+	}
+
+	keys := &Keys{byHash: make(map[string]Key)}
+	if extra != nil {
+		for hash, key := range extra.byHash {
+			keys.byHash[hash] = key
+		}
+	}
+	if err := keys.add(string(data)); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return keys, nil
+}
+
+// Len returns the number of keys
+func (k *Keys) Len() int {
+	if k == nil {
+		return 0
+	}
+	return len(k.byHash)
+}
+
+// Authenticate returns the key matching a presented key
+func (k *Keys) Authenticate(presented string) (Key, bool) {
+	if k == nil || presented == "" {
+		return Key{}, false
+	}
+	key, ok := k.byHash[HashKey(presented)]
+	return key, ok
+}
+
+// add parses entries into k
+func (k *Keys) add(spec string) error {
+	scanner := bufio.NewScanner(strings.NewReader(strings.ReplaceAll(spec, ",", "\n")))
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 {
+			return fmt.Errorf("API key entry %d: expected name:role:sha256-hex", line)
+		}
+		name, role, hash := parts[0], Role(parts[1]), strings.ToLower(parts[2])
+
+		if name == "" {
+			return fmt.Errorf("API key entry %d: empty name", line)
+		}
+		if role != RoleRead && role != RoleAdmin {
+			return fmt.Errorf("API key entry %d (%s): role must be %s or %s", line, name, RoleRead, RoleAdmin)
+		}
+		if b, err := hex.DecodeString(hash); err != nil || len(b) != sha256.Size {
+			return fmt.Errorf("API key entry %d (%s): hash must be 64 hex digits", line, name)
+		}
+
+		k.byHash[hash] = Key{Name: name, Role: role}
+	}
+
+	return scanner.Err()
+}
+
+// contextKey is the type of the context key holding the authenticated key
+type contextKey struct{}
+
+// WithKey returns a context carrying the authenticated key
+func WithKey(ctx context.Context, key Key) context.Context {
+	return context.WithValue(ctx, contextKey{}, key)
+}
+
+// FromContext returns the authenticated key of a request context
+func FromContext(ctx context.Context) (Key, bool) {
+	key, ok := ctx.Value(contextKey{}).(Key)
+	return key, ok
+}
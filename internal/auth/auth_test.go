@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	spec := "erp:admin:" + HashKey("admin-key") + ", dashboard:read:" + strings.ToUpper(HashKey("read-key"))
+	keys, err := ParseKeys(spec)
+	if err != nil {
+		t.Fatalf("ParseKeys failed: %v", err)
+	}
+
+	tests := []struct {
+		presented string
+		wantOK    bool
+		wantKey   Key
+	}{
+		{"admin-key", true, Key{Name: "erp", Role: RoleAdmin}},
+		{"read-key", true, Key{Name: "dashboard", Role: RoleRead}},
+		{"wrong-key", false, Key{}},
+		{"", false, Key{}},
+		{HashKey("admin-key"), false, Key{}}, // The hash itself is not a key
+	}
+
+	for _, tt := range tests {
+		key, ok := keys.Authenticate(tt.presented)
+		if ok != tt.wantOK || key != tt.wantKey {
+			t.Errorf("Authenticate(%q) = %+v, %v; want %+v, %v", tt.presented, key, ok, tt.wantKey, tt.wantOK)
+		}
+	}
+}
+
+func TestParseKeysInvalid(t *testing.T) {
+	tests := []string{
+		"erp:admin",
+		"erp:owner:" + HashKey("key"),
+		":read:" + HashKey("key"),
+		"erp:read:not-hex",
+		"erp:read:" + HashKey("key")[:32],
+	}
+
+	for _, spec := range tests {
+		if _, err := ParseKeys(spec); err == nil {
+			t.Errorf("ParseKeys(%q): expected an error", spec)
+		}
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "api_keys")
+	content := "# Warehouse systems\nerp:admin:" + HashKey("admin-key") + "\n\nscanner:read:" + HashKey("read-key") + "\n"
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write keys file: %v", err)
+	}
+
+	fromEnv, _ := ParseKeys("dashboard:read:" + HashKey("env-key"))
+	keys, err := LoadKeys(file, fromEnv)
+	if err != nil {
+		t.Fatalf("LoadKeys failed: %v", err)
+	}
+
+	if keys.Len() != 3 {
+		t.Errorf("Expected 3 keys, got %d", keys.Len())
+	}
+	if key, ok := keys.Authenticate("env-key"); !ok || key.Name != "dashboard" {
+		t.Errorf("Expected the key from the environment to be kept, got %+v", key)
+	}
+}
+
+func TestRoleAllows(t *testing.T) {
+	if !RoleAdmin.Allows(RoleRead) || !RoleAdmin.Allows(RoleAdmin) || !RoleRead.Allows(RoleRead) {
+		t.Error("Expected admin to allow everything and read to allow read")
+	}
+	if RoleRead.Allows(RoleAdmin) {
+		t.Error("Expected read not to allow admin")
+	}
+}
+
+func TestContext(t *testing.T) {
+	if _, ok := FromContext(context.Background()); ok {
+		t.Error("Expected no key in a plain context")
+	}
+
+	ctx := WithKey(context.Background(), Key{Name: "erp", Role: RoleAdmin})
+	if key, ok := FromContext(ctx); !ok || key.Name != "erp" {
+		t.Errorf("Expected key erp, got %+v", key)
+	}
+}
@@ -1,93 +1,75 @@
 package calculator
 
 import (
+	"order-pack-calculator/internal/model"
+	"order-pack-calculator/pkg/packing"
 	"sort"
 )
 
-// Solution represents a pack combination with its metrics
-type Solution struct {
-	TotalItems int
-	PackCount  int
-	Packs      map[int]int // pack size -> quantity
-}
-
 // CalculatePacks calculates the optimal pack combination for an order
 // Rules (in priority):
 // 1. Only whole packs
 // 2. Minimize excess items (total items - order quantity)
 // 3. Minimize pack count (among solutions with same total items)
+// Invalid pack sizes are skipped; an empty map is returned if none remain
+// or the order quantity is not positive.
 func CalculatePacks(orderQty int, packSizes []int) map[int]int {
-	// Edge case: zero order returns empty result
-	if orderQty <= 0 {
-		return make(map[int]int)
-	}
+	packs := make(map[int]int)
 
-	// Edge case: no pack sizes available
-	if len(packSizes) == 0 {
-		return make(map[int]int)
+	result, err := solve(orderQty, packSizes)
+	if err != nil {
+		return packs
 	}
 
-	// Remove duplicates and sort in descending order for optimization
-	uniqueSizes := removeDuplicates(packSizes)
-	sort.Sort(sort.Reverse(sort.IntSlice(uniqueSizes)))
-
-	// Find the maximum pack size to determine upper bound
-	maxPack := uniqueSizes[0]
-
-	// Upper bound: we never need more excess than the largest pack
-	upperBound := orderQty + maxPack
+	for _, pack := range result.Packs {
+		packs[pack.Size] = pack.Quantity
+	}
 
-	// dp[i] stores the best solution to reach exactly i items
-	dp := make([]*Solution, upperBound+1)
+	return packs
+}
 
-	// Base case: 0 items requires 0 packs
-	dp[0] = &Solution{
-		TotalItems: 0,
-		PackCount:  0,
-		Packs:      make(map[int]int),
+// Calculate calculates the optimal pack combination for an order and
+// returns it as an API response, largest packs first
+func Calculate(orderQty int, packSizes []int) model.CalculateResponse {
+	result, err := solve(orderQty, packSizes)
+	if err != nil {
+		return model.CalculateResponse{OrderQuantity: orderQty, Packs: []model.PackBreakdown{}}
 	}
 
-	// Fill DP table
-	for amount := 1; amount <= upperBound; amount++ {
-		for _, packSize := range uniqueSizes {
-			if amount >= packSize && dp[amount-packSize] != nil {
-				// Create candidate solution by adding one pack
-				candidate := &Solution{
-					TotalItems: amount,
-					PackCount:  dp[amount-packSize].PackCount + 1,
-					Packs:      copyMap(dp[amount-packSize].Packs),
-				}
-				candidate.Packs[packSize]++
+	return Response(result)
+}
 
-				// Update dp[amount] if this candidate is better
-				if dp[amount] == nil || candidate.PackCount < dp[amount].PackCount {
-					dp[amount] = candidate
-				}
-			}
-		}
+// Response converts a solver result to an API response
+func Response(result packing.Result) model.CalculateResponse {
+	packs := make([]model.PackBreakdown, 0, len(result.Packs))
+	for _, pack := range result.Packs {
+		packs = append(packs, model.PackBreakdown{
+			Size:     pack.Size,
+			Quantity: pack.Quantity,
+		})
 	}
 
-	// Find best solution: minimum items >= orderQty, then minimum packs
-	var bestSolution *Solution
-	for amount := orderQty; amount <= upperBound; amount++ {
-		if dp[amount] != nil {
-			if bestSolution == nil ||
-				amount < bestSolution.TotalItems ||
-				(amount == bestSolution.TotalItems && dp[amount].PackCount < bestSolution.PackCount) {
-				bestSolution = dp[amount]
-			}
-		}
+	return model.CalculateResponse{
+		OrderQuantity: result.OrderQuantity,
+		Packs:         packs,
+		TotalItems:    result.TotalItems,
+		TotalPacks:    result.TotalPacks,
 	}
+}
 
-	if bestSolution == nil {
-		return make(map[int]int)
+// solve calculates packs with the default objective, skipping invalid
+// pack sizes
+func solve(orderQty int, packSizes []int) (packing.Result, error) {
+	solver, err := packing.NewSolver(UniqueDescending(packSizes))
+	if err != nil {
+		return packing.Result{}, err
 	}
 
-	return bestSolution.Packs
+	return solver.Solve(orderQty)
 }
 
-// removeDuplicates removes duplicate pack sizes
-func removeDuplicates(sizes []int) []int {
+// UniqueDescending returns the distinct positive sizes, largest first
+func UniqueDescending(sizes []int) []int {
 	seen := make(map[int]bool)
 	result := []int{}
 
@@ -97,15 +79,7 @@ func removeDuplicates(sizes []int) []int {
 			result = append(result, size)
 		}
 	}
+	sort.Sort(sort.Reverse(sort.IntSlice(result)))
 
 	return result
 }
-
-// copyMap creates a deep copy of a map
-func copyMap(m map[int]int) map[int]int {
-	result := make(map[int]int)
-	for k, v := range m {
-		result[k] = v
-	}
-	return result
-}
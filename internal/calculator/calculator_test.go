@@ -1,7 +1,9 @@
 package calculator
 
 import (
+	"order-pack-calculator/internal/model"
 	"reflect"
+	"slices"
 	"testing"
 )
 
@@ -163,7 +165,24 @@ func TestCalculatePacks(t *testing.T) {
 	}
 }
 
-func TestRemoveDuplicates(t *testing.T) {
+func TestCalculate(t *testing.T) {
+	got := Calculate(12001, []int{250, 500, 1000, 2000, 5000})
+
+	wantPacks := []model.PackBreakdown{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 250, Quantity: 1}}
+	if !reflect.DeepEqual(got.Packs, wantPacks) {
+		t.Errorf("Calculate() packs = %v, want %v", got.Packs, wantPacks)
+	}
+	if got.OrderQuantity != 12001 || got.TotalItems != 12250 || got.TotalPacks != 4 {
+		t.Errorf("Calculate() = %+v, want 12250 items in 4 packs", got)
+	}
+
+	// Empty results still encode as an empty list
+	if got := Calculate(0, []int{250}); got.Packs == nil || len(got.Packs) != 0 {
+		t.Errorf("Calculate() packs = %#v, want empty slice", got.Packs)
+	}
+}
+
+func TestUniqueDescending(t *testing.T) {
 	tests := []struct {
 		name  string
 		input []int
@@ -172,12 +191,12 @@ func TestRemoveDuplicates(t *testing.T) {
 		{
 			name:  "no duplicates",
 			input: []int{1, 2, 3},
-			want:  []int{1, 2, 3},
+			want:  []int{3, 2, 1},
 		},
 		{
 			name:  "with duplicates",
 			input: []int{1, 2, 2, 3, 1},
-			want:  []int{1, 2, 3},
+			want:  []int{3, 2, 1},
 		},
 		{
 			name:  "empty slice",
@@ -198,46 +217,10 @@ func TestRemoveDuplicates(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := removeDuplicates(tt.input)
-			if !equalSlices(got, tt.want) {
-				t.Errorf("removeDuplicates() = %v, want %v", got, tt.want)
+			got := UniqueDescending(tt.input)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("UniqueDescending() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
-
-func TestCopyMap(t *testing.T) {
-	original := map[int]int{1: 2, 3: 4}
-	copied := copyMap(original)
-
-	// Modify original
-	original[5] = 6
-
-	// Check that copied wasn't affected
-	if _, exists := copied[5]; exists {
-		t.Error("copyMap() did not create a deep copy")
-	}
-
-	if len(copied) != 2 {
-		t.Errorf("copyMap() length = %d, want 2", len(copied))
-	}
-}
-
-// Helper function to compare slices ignoring order
-func equalSlices(a, b []int) bool {
-	if len(a) != len(b) {
-		return false
-	}
-
-	countA := make(map[int]int)
-	countB := make(map[int]int)
-
-	for _, v := range a {
-		countA[v]++
-	}
-	for _, v := range b {
-		countB[v]++
-	}
-
-	return reflect.DeepEqual(countA, countB)
-}
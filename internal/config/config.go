@@ -0,0 +1,88 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// DefaultPackSizes is used when PACK_SIZES is unset or invalid
+const DefaultPackSizes = "250,500,1000,2000,5000"
+
+// LoadDotEnv loads a .env file from the working directory if it exists
+// Returns an error if the file is missing or unreadable
+func LoadDotEnv() error {
+	return godotenv.Load()
+}
+
+// GetEnv gets environment variable with fallback
+func GetEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// ParsePackSizes parses comma-separated pack sizes
+// Invalid entries are skipped; the defaults are returned if none are valid
+func ParsePackSizes(s string) []int {
+	sizes := parseSizes(s)
+
+	// Default if parsing failed
+	if len(sizes) == 0 {
+		return parseSizes(DefaultPackSizes)
+	}
+
+	return sizes
+}
+
+// GetDuration gets a duration environment variable such as "5s"
+// The fallback is used if it is unset or invalid, so a typo never turns a
+// timeout off; only an explicit "0s" does.
+func GetDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		slog.Warn("Invalid duration, using the default", "key", key, "value", value, "default", fallback.String())
+		return fallback
+	}
+	return d
+}
+
+// GetInt gets an integer environment variable
+// The fallback is used if it is unset or invalid, so a typo never turns a
+// limit off; only an explicit "0" does.
+func GetInt(key string, fallback int64) int64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		slog.Warn("Invalid integer, using the default", "key", key, "value", value, "default", fallback)
+		return fallback
+	}
+	return n
+}
+
+// parseSizes returns the positive integers in a comma-separated list
+func parseSizes(s string) []int {
+	parts := strings.Split(s, ",")
+	sizes := make([]int, 0, len(parts))
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if num, err := strconv.Atoi(part); err == nil && num > 0 {
+			sizes = append(sizes, num)
+		}
+	}
+
+	return sizes
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetInt(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  int64
+	}{
+		{"unset", "", 100},
+		{"valid", "42", 42},
+		{"explicit zero", "0", 0},
+		{"padded", " 7 ", 7},
+		{"invalid", "10MB", 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_INT", tt.value)
+			if got := GetInt("TEST_INT", 100); got != tt.want {
+				t.Errorf("Expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGetDuration(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{"unset", "", 10 * time.Second},
+		{"valid", "5s", 5 * time.Second},
+		{"explicit zero", "0", 0},
+		{"invalid", "5 s", 10 * time.Second},
+		{"missing unit", "30", 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_DURATION", tt.value)
+			if got := GetDuration("TEST_DURATION", 10*time.Second); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
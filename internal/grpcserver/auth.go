@@ -0,0 +1,90 @@
+package grpcserver
+
+import (
+	"context"
+	"net/http"
+	"order-pack-calculator/internal/auth"
+	"order-pack-calculator/internal/handler"
+	"order-pack-calculator/internal/model"
+	packcalcv1 "order-pack-calculator/proto/packcalc/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// APIKeyMetadata is the metadata key carrying the API key of a call
+const APIKeyMetadata = "x-api-key"
+
+// methodRoles are the roles API keys need for methods other than reads
+var methodRoles = map[string]auth.Role{
+	packcalcv1.PackCalculator_UpdatePackSizes_FullMethodName: auth.RoleAdmin,
+}
+
+// ServerOptions returns the options requiring every call to carry one of
+// keys with a role allowing the method. Without keys the service is open.
+func ServerOptions(keys *auth.Keys) []grpc.ServerOption {
+	if keys.Len() == 0 {
+		return nil
+	}
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			ctx, err := authorize(ctx, keys, info.FullMethod)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, err := authorize(ss.Context(), keys, info.FullMethod)
+			if err != nil {
+				return err
+			}
+			return handler(srv, &authorizedStream{ServerStream: ss, ctx: ctx})
+		}),
+	}
+}
+
+// authorize checks the API key of a call against the role its method
+// requires and returns the context with the key
+func authorize(ctx context.Context, keys *auth.Keys, method string) (context.Context, error) {
+	var presented string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(APIKeyMetadata); len(values) > 0 {
+			presented = values[0]
+		}
+	}
+
+	key, ok := keys.Authenticate(presented)
+	if !ok {
+		return nil, statusError(model.ErrorResponse{
+			Status: http.StatusUnauthorized,
+			Detail: "Missing or invalid API key",
+			Code:   handler.CodeUnauthorized,
+		})
+	}
+
+	role, ok := methodRoles[method]
+	if !ok {
+		role = auth.RoleRead
+	}
+	if !key.Role.Allows(role) {
+		return nil, statusError(model.ErrorResponse{
+			Status: http.StatusForbidden,
+			Detail: "The API key does not allow this operation",
+			Code:   handler.CodeForbidden,
+		})
+	}
+
+	return auth.WithKey(ctx, key), nil
+}
+
+// authorizedStream is a server stream whose context carries the API key
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,57 @@
+package grpcserver
+
+import (
+	"context"
+	"order-pack-calculator/internal/auth"
+	"order-pack-calculator/internal/handler"
+	packcalcv1 "order-pack-calculator/proto/packcalc/v1"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	keys, err := auth.ParseKeys("erp:admin:" + auth.HashKey("admin-key") + ",dashboard:read:" + auth.HashKey("read-key"))
+	if err != nil {
+		t.Fatalf("ParseKeys failed: %v", err)
+	}
+	client := newTestClient(t, handler.NewHandler([]int{250, 500}), ServerOptions(keys)...)
+
+	withKey := func(key string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), APIKeyMetadata, key)
+	}
+	update := &packcalcv1.UpdatePackSizesRequest{PackSizes: []int64{100}}
+
+	// Missing and unknown keys
+	for _, ctx := range []context.Context{context.Background(), withKey("other-key")} {
+		_, err := client.GetPackSizes(ctx, &packcalcv1.GetPackSizesRequest{})
+		if st, info := errorInfo(t, err); st.Code() != codes.Unauthenticated || info.Reason != "unauthorized" {
+			t.Errorf("Expected Unauthenticated/unauthorized, got %v/%s", st.Code(), info.Reason)
+		}
+	}
+
+	// Read keys calculate but cannot update
+	if _, err := client.Calculate(withKey("read-key"), &packcalcv1.CalculateRequest{OrderQuantity: proto.Int64(251)}); err != nil {
+		t.Errorf("Calculate with a read key failed: %v", err)
+	}
+	_, err = client.UpdatePackSizes(withKey("read-key"), update)
+	if st, info := errorInfo(t, err); st.Code() != codes.PermissionDenied || info.Reason != "forbidden" {
+		t.Errorf("Expected PermissionDenied/forbidden, got %v/%s", st.Code(), info.Reason)
+	}
+
+	// Admin keys update
+	if _, err := client.UpdatePackSizes(withKey("admin-key"), update); err != nil {
+		t.Errorf("UpdatePackSizes with an admin key failed: %v", err)
+	}
+
+	// Streams are checked too
+	stream, err := client.CalculateBatch(context.Background())
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if st, _ := errorInfo(t, err); st.Code() != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated for a stream without a key, got %v", st.Code())
+	}
+}
@@ -0,0 +1,248 @@
+// Package grpcserver serves the pack calculator over gRPC
+//
+// The service shares its state with the HTTP API: pack size changes made
+// through either are seen by both, and calculations go through the same
+// solver, result cache and order history.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"order-pack-calculator/internal/handler"
+	"order-pack-calculator/internal/logging"
+	"order-pack-calculator/internal/model"
+	packcalcv1 "order-pack-calculator/proto/packcalc/v1"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain is the domain of the ErrorInfo details attached to errors
+const ErrorDomain = "order-pack-calculator"
+
+// RequestIDMetadata is the metadata key carrying the request ID of a call,
+// recorded in the audit log
+const RequestIDMetadata = "x-request-id"
+
+// Server implements the PackCalculator gRPC service on top of a Handler
+type Server struct {
+	packcalcv1.UnimplementedPackCalculatorServer
+
+	handler *handler.Handler
+}
+
+// New creates a gRPC service backed by h
+func New(h *handler.Handler) *Server {
+	return &Server{handler: h}
+}
+
+// Register registers the service, backed by h, with s
+func Register(s grpc.ServiceRegistrar, h *handler.Handler) {
+	packcalcv1.RegisterPackCalculatorServer(s, New(h))
+}
+
+// GetPackSizes returns the current pack sizes
+func (s *Server) GetPackSizes(ctx context.Context, req *packcalcv1.GetPackSizesRequest) (*packcalcv1.PackSizes, error) {
+	sizes, version := s.handler.PackSizes()
+	return packSizesMessage(sizes, version), nil
+}
+
+// UpdatePackSizes replaces the pack sizes
+func (s *Server) UpdatePackSizes(ctx context.Context, req *packcalcv1.UpdatePackSizesRequest) (*packcalcv1.PackSizes, error) {
+	sizes := make([]int, len(req.GetPackSizes()))
+	for i, size := range req.GetPackSizes() {
+		sizes[i] = int(size)
+	}
+
+	version, err := s.handler.SetPackSizes(withCaller(ctx), sizes)
+	if err != nil {
+		return nil, statusError(handler.Problem(err))
+	}
+
+	return packSizesMessage(sizes, version), nil
+}
+
+// withCaller returns ctx carrying the peer address and request ID of a call
+// for the audit log
+func withCaller(ctx context.Context) context.Context {
+	var caller handler.Caller
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		caller.SourceIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(caller.SourceIP); err == nil {
+			caller.SourceIP = host
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(RequestIDMetadata); len(ids) > 0 && logging.ValidRequestID(ids[0]) {
+			caller.RequestID = ids[0]
+		}
+	}
+
+	return handler.WithCaller(ctx, caller)
+}
+
+// Calculate calculates the packs for an order
+func (s *Server) Calculate(ctx context.Context, req *packcalcv1.CalculateRequest) (*packcalcv1.CalculateResponse, error) {
+	response, err := s.calculate(ctx, req)
+	if err != nil {
+		return nil, statusError(*err)
+	}
+
+	return response, nil
+}
+
+// CalculateBatch calculates the packs for a stream of orders
+// Failed orders are answered with an error result; the stream only ends
+// early if it breaks or the client goes away.
+func (s *Server) CalculateBatch(stream packcalcv1.PackCalculator_CalculateBatchServer) error {
+	for index := int64(0); ; index++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		result := &packcalcv1.CalculateBatchResponse{Index: index}
+		response, problem := s.calculate(stream.Context(), req)
+		if problem != nil {
+			result.Result = &packcalcv1.CalculateBatchResponse_Error{Error: &packcalcv1.Error{
+				Code:    problem.Code,
+				Message: problem.Detail,
+				Field:   problem.Field,
+			}}
+		} else {
+			result.Result = &packcalcv1.CalculateBatchResponse_Response{Response: response}
+		}
+
+		if err := stream.Send(result); err != nil {
+			return err
+		}
+	}
+}
+
+// calculate calculates one order, reporting failures as the problem the
+// HTTP API would respond with
+func (s *Server) calculate(ctx context.Context, req *packcalcv1.CalculateRequest) (*packcalcv1.CalculateResponse, *model.ErrorResponse) {
+	if req.OrderQuantity == nil {
+		return nil, &model.ErrorResponse{
+			Status: http.StatusBadRequest,
+			Detail: "Missing required field: order_quantity",
+			Error:  "Missing required field: order_quantity",
+			Code:   handler.CodeMissingField,
+			Field:  "order_quantity",
+		}
+	}
+
+	response, err := s.handler.Calculate(ctx, model.CalculateRequest{
+		OrderQuantity: int(req.GetOrderQuantity()),
+		ClientOrderID: req.GetClientOrderId(),
+		SKU:           req.GetSku(),
+	})
+	if err != nil {
+		problem := handler.Problem(err)
+		return nil, &problem
+	}
+
+	return calculateResponseMessage(response), nil
+}
+
+// packSizesMessage converts pack sizes to their message
+func packSizesMessage(sizes []int, version int64) *packcalcv1.PackSizes {
+	msg := &packcalcv1.PackSizes{
+		PackSizes: make([]int64, len(sizes)),
+		Version:   version,
+	}
+	for i, size := range sizes {
+		msg.PackSizes[i] = int64(size)
+	}
+
+	return msg
+}
+
+// calculateResponseMessage converts a calculation result to its message
+func calculateResponseMessage(response model.CalculateResponse) *packcalcv1.CalculateResponse {
+	msg := &packcalcv1.CalculateResponse{
+		OrderId:       response.OrderID,
+		ClientOrderId: response.ClientOrderID,
+		Sku:           response.SKU,
+		OrderQuantity: int64(response.OrderQuantity),
+		Packs:         make([]*packcalcv1.PackBreakdown, len(response.Packs)),
+		TotalItems:    int64(response.TotalItems),
+		TotalPacks:    int64(response.TotalPacks),
+	}
+	for i, pack := range response.Packs {
+		msg.Packs[i] = &packcalcv1.PackBreakdown{Size: int64(pack.Size), Quantity: int64(pack.Quantity)}
+	}
+
+	return msg
+}
+
+// statusError converts a problem to a gRPC status error carrying its code
+// as ErrorInfo and its field errors as BadRequest details
+func statusError(problem model.ErrorResponse) error {
+	st := status.New(statusCode(problem), problem.Detail)
+
+	info := &errdetails.ErrorInfo{Reason: problem.Code, Domain: ErrorDomain}
+	if problem.Field != "" {
+		info.Metadata = map[string]string{"field": problem.Field}
+	}
+
+	var badRequest *errdetails.BadRequest
+	for _, fieldErr := range problem.Errors {
+		if badRequest == nil {
+			badRequest = &errdetails.BadRequest{}
+		}
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       fieldErr.Field,
+			Description: fieldErr.Message,
+			Reason:      fieldErr.Code,
+		})
+	}
+
+	withDetails, err := st.WithDetails(info)
+	if err == nil && badRequest != nil {
+		withDetails, err = withDetails.WithDetails(badRequest)
+	}
+	if err != nil {
+		return st.Err()
+	}
+
+	return withDetails.Err()
+}
+
+// statusCode picks the gRPC code closest to a problem's HTTP status
+func statusCode(problem model.ErrorResponse) codes.Code {
+	switch problem.Status {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusRequestEntityTooLarge:
+		return codes.OutOfRange
+	case http.StatusUnprocessableEntity:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		switch problem.Code {
+		case handler.CodeCalculationTimeout:
+			return codes.DeadlineExceeded
+		case handler.CodeCalculationCancelled:
+			return codes.Canceled
+		}
+		return codes.Unavailable
+	}
+
+	return codes.Internal
+}
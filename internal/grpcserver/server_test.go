@@ -0,0 +1,245 @@
+package grpcserver
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"order-pack-calculator/internal/handler"
+	packcalcv1 "order-pack-calculator/proto/packcalc/v1"
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// newTestClient serves h over an in-memory listener and returns a client
+func newTestClient(t *testing.T, h *handler.Handler, opts ...grpc.ServerOption) packcalcv1.PackCalculatorClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(opts...)
+	Register(srv, h)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return packcalcv1.NewPackCalculatorClient(conn)
+}
+
+// errorInfo returns the ErrorInfo detail of a status error
+func errorInfo(t *testing.T, err error) (*status.Status, *errdetails.ErrorInfo) {
+	t.Helper()
+
+	st, ok := status.FromError(err)
+	if !ok {
+		t.Fatalf("Expected a status error, got %v", err)
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return st, info
+		}
+	}
+	t.Fatalf("Expected an ErrorInfo detail in %v", st)
+	return nil, nil
+}
+
+func TestGetPackSizes(t *testing.T) {
+	client := newTestClient(t, handler.NewHandler([]int{250, 500, 1000}))
+
+	resp, err := client.GetPackSizes(context.Background(), &packcalcv1.GetPackSizesRequest{})
+	if err != nil {
+		t.Fatalf("GetPackSizes failed: %v", err)
+	}
+
+	if len(resp.PackSizes) != 3 || resp.PackSizes[0] != 250 {
+		t.Errorf("Expected pack sizes [250 500 1000], got %v", resp.PackSizes)
+	}
+}
+
+func TestUpdatePackSizes(t *testing.T) {
+	h := handler.NewHandler([]int{250, 500})
+	client := newTestClient(t, h)
+
+	resp, err := client.UpdatePackSizes(context.Background(), &packcalcv1.UpdatePackSizesRequest{PackSizes: []int64{23, 31, 53}})
+	if err != nil {
+		t.Fatalf("UpdatePackSizes failed: %v", err)
+	}
+	if resp.Version != 1 {
+		t.Errorf("Expected version 1, got %d", resp.Version)
+	}
+
+	// The HTTP API shares the same pack sizes
+	w := httptest.NewRecorder()
+	h.CalculatePacks(w, httptest.NewRequest(http.MethodPost, "/api/v1/calculate", strings.NewReader(`{"order_quantity": 500000}`)))
+	if !strings.Contains(w.Body.String(), `"total_items":500000`) {
+		t.Errorf("Expected HTTP calculation with the new pack sizes, got %s", w.Body.String())
+	}
+}
+
+func TestUpdatePackSizesAudited(t *testing.T) {
+	h := handler.NewHandler([]int{250, 500})
+	client := newTestClient(t, h)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), RequestIDMetadata, "req-7")
+	if _, err := client.UpdatePackSizes(ctx, &packcalcv1.UpdatePackSizesRequest{PackSizes: []int64{23, 31, 53}}); err != nil {
+		t.Fatalf("UpdatePackSizes failed: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	h.NewRouter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, handler.APIPrefix+"/audit", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, `"action":"pack_sizes.updated"`) || !strings.Contains(body, `"request_id":"req-7"`) {
+		t.Errorf("Expected the change to be audited with request ID req-7, got %s", body)
+	}
+}
+
+func TestUpdatePackSizesInvalid(t *testing.T) {
+	client := newTestClient(t, handler.NewHandler([]int{250, 500}))
+
+	_, err := client.UpdatePackSizes(context.Background(), &packcalcv1.UpdatePackSizesRequest{PackSizes: []int64{100, -5, 0}})
+	st, info := errorInfo(t, err)
+
+	if st.Code() != codes.InvalidArgument {
+		t.Errorf("Expected code InvalidArgument, got %v", st.Code())
+	}
+	if info.Reason != "invalid_pack_sizes" || info.Domain != ErrorDomain {
+		t.Errorf("Expected reason invalid_pack_sizes in %s, got %s in %s", ErrorDomain, info.Reason, info.Domain)
+	}
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			violations = badRequest.FieldViolations
+		}
+	}
+	if len(violations) != 2 || violations[0].Field != "pack_sizes[1]" || violations[1].Field != "pack_sizes[2]" {
+		t.Errorf("Expected violations for pack_sizes[1] and pack_sizes[2], got %v", violations)
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	client := newTestClient(t, handler.NewHandler([]int{250, 500, 1000, 2000, 5000}))
+
+	resp, err := client.Calculate(context.Background(), &packcalcv1.CalculateRequest{
+		OrderQuantity: proto.Int64(12001),
+		ClientOrderId: "PO-1",
+		Sku:           "WIDGET",
+	})
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	if resp.TotalItems != 12250 || resp.TotalPacks != 4 {
+		t.Errorf("Expected 12250 items in 4 packs, got %d in %d", resp.TotalItems, resp.TotalPacks)
+	}
+	if resp.ClientOrderId != "PO-1" || resp.Sku != "WIDGET" {
+		t.Errorf("Expected client order ID and SKU to be echoed, got %q and %q", resp.ClientOrderId, resp.Sku)
+	}
+	if len(resp.Packs) == 0 || resp.Packs[0].Size != 5000 || resp.Packs[0].Quantity != 2 {
+		t.Errorf("Expected 2x5000 first, got %v", resp.Packs)
+	}
+}
+
+func TestCalculateErrors(t *testing.T) {
+	client := newTestClient(t, handler.NewHandler([]int{250, 500}))
+
+	tests := []struct {
+		name   string
+		req    *packcalcv1.CalculateRequest
+		code   codes.Code
+		reason string
+	}{
+		{"missing quantity", &packcalcv1.CalculateRequest{}, codes.InvalidArgument, "missing_field"},
+		{"negative quantity", &packcalcv1.CalculateRequest{OrderQuantity: proto.Int64(-1)}, codes.InvalidArgument, "invalid_order_quantity"},
+		{"quantity too large", &packcalcv1.CalculateRequest{OrderQuantity: proto.Int64(handler.DefaultMaxOrderQuantity + 1)}, codes.OutOfRange, "order_quantity_too_large"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Calculate(context.Background(), tt.req)
+			st, info := errorInfo(t, err)
+
+			if st.Code() != tt.code {
+				t.Errorf("Expected code %v, got %v", tt.code, st.Code())
+			}
+			if info.Reason != tt.reason {
+				t.Errorf("Expected reason %s, got %s", tt.reason, info.Reason)
+			}
+			if info.Metadata["field"] != "order_quantity" {
+				t.Errorf("Expected field order_quantity, got %q", info.Metadata["field"])
+			}
+		})
+	}
+}
+
+func TestCalculateBatch(t *testing.T) {
+	client := newTestClient(t, handler.NewHandler([]int{250, 500, 1000}))
+
+	stream, err := client.CalculateBatch(context.Background())
+	if err != nil {
+		t.Fatalf("CalculateBatch failed: %v", err)
+	}
+
+	quantities := []*int64{proto.Int64(1), proto.Int64(-1), nil, proto.Int64(501)}
+	for _, qty := range quantities {
+		if err := stream.Send(&packcalcv1.CalculateRequest{OrderQuantity: qty}); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("CloseSend failed: %v", err)
+	}
+
+	var results []*packcalcv1.CalculateBatchResponse
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		results = append(results, resp)
+	}
+
+	if len(results) != len(quantities) {
+		t.Fatalf("Expected %d results, got %d", len(quantities), len(results))
+	}
+	for i, resp := range results {
+		if resp.Index != int64(i) {
+			t.Errorf("Expected index %d, got %d", i, resp.Index)
+		}
+	}
+
+	if got := results[0].GetResponse().GetTotalItems(); got != 250 {
+		t.Errorf("Expected 250 items for order 0, got %d", got)
+	}
+	if got := results[1].GetError().GetCode(); got != "invalid_order_quantity" {
+		t.Errorf("Expected invalid_order_quantity for order 1, got %q", got)
+	}
+	if got := results[2].GetError().GetCode(); got != "missing_field" {
+		t.Errorf("Expected missing_field for order 2, got %q", got)
+	}
+	if got := results[3].GetResponse().GetTotalItems(); got != 750 {
+		t.Errorf("Expected 750 items for order 3, got %d", got)
+	}
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"order-pack-calculator/internal/analytics"
+)
+
+// GetAnalytics returns totals and a time series over recorded calculations
+// Query parameters: from, to (RFC 3339), sku, version, bucket (day, week or
+// month; defaults to week)
+func (h *Handler) GetAnalytics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendError(w, errMethodNotAllowed)
+		return
+	}
+
+	if h.history == nil {
+		sendError(w, errHistoryDisabled)
+		return
+	}
+
+	filter, err := parseOrderSelection(r)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	bucket := r.URL.Query().Get("bucket")
+	if bucket == "" {
+		bucket = analytics.Week
+	}
+
+	orders, _, err := h.history.List(filter)
+	if err != nil {
+		h.metrics.storageError(opReadOrders)
+		slog.ErrorContext(r.Context(), "Failed to list orders", "error", err)
+		sendError(w, errHistoryRead)
+		return
+	}
+
+	response, err := analytics.Summarize(orders, bucket)
+	if err != nil {
+		sendError(w, errBadParam("bucket"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"order-pack-calculator/internal/model"
+	"testing"
+)
+
+func TestGetAnalytics(t *testing.T) {
+	handler := newHistoryHandler(t)
+	calculateOrder(t, handler, model.CalculateRequest{OrderQuantity: 251, SKU: "A"})
+	calculateOrder(t, handler, model.CalculateRequest{OrderQuantity: 501, SKU: "A"})
+	calculateOrder(t, handler, model.CalculateRequest{OrderQuantity: 250, SKU: "B"})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/analytics?sku=A&bucket=day", nil)
+	w := httptest.NewRecorder()
+	handler.GetAnalytics(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var response model.AnalyticsResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Bucket != "day" || len(response.Series) != 1 {
+		t.Errorf("Expected a single day bucket, got %q with %d buckets", response.Bucket, len(response.Series))
+	}
+	if response.Totals.Orders != 2 || response.Totals.ItemsRequested != 752 || response.Totals.ExcessItems != 498 {
+		t.Errorf("Unexpected totals for SKU A: %+v", response.Totals)
+	}
+}
+
+func TestGetAnalyticsVersionFilter(t *testing.T) {
+	handler := newHistoryHandler(t)
+	calculateOrder(t, handler, model.CalculateRequest{OrderQuantity: 251})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/analytics?version=7", nil)
+	w := httptest.NewRecorder()
+	handler.GetAnalytics(w, req)
+
+	var response model.AnalyticsResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Totals.Orders != 0 {
+		t.Errorf("Expected no orders for unknown version, got %d", response.Totals.Orders)
+	}
+}
+
+func TestGetAnalyticsInvalid(t *testing.T) {
+	handler := newHistoryHandler(t)
+
+	for _, query := range []string{"bucket=year", "version=abc", "from=yesterday"} {
+		t.Run(query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/analytics?"+query, nil)
+			w := httptest.NewRecorder()
+			handler.GetAnalytics(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"order-pack-calculator/internal/auth"
+	"order-pack-calculator/internal/logging"
+	"order-pack-calculator/internal/model"
+	"order-pack-calculator/internal/storage"
+)
+
+// Audited actions
+const (
+	AuditPackSizesUpdated      = "pack_sizes.updated"
+	AuditWebhookCreated        = "webhook.created"
+	AuditWebhookDeleted        = "webhook.deleted"
+	AuditDeadLetterRedelivered = "dead_letter.redelivered"
+)
+
+// AuditActions lists every audited action
+var AuditActions = []string{AuditPackSizesUpdated, AuditWebhookCreated, AuditWebhookDeleted, AuditDeadLetterRedelivered}
+
+// anonymousActor is the actor of changes made without API keys configured
+const anonymousActor = "anonymous"
+
+// Caller identifies where a request came from
+type Caller struct {
+	SourceIP  string
+	RequestID string
+}
+
+// callerKey is the context key holding the caller
+type callerKey struct{}
+
+// WithCaller returns a context carrying the caller of a request
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// callerFrom returns the caller of a request context
+func callerFrom(ctx context.Context) Caller {
+	caller, _ := ctx.Value(callerKey{}).(Caller)
+	return caller
+}
+
+// withCaller identifies the caller of an HTTP request by its address and
+// the request ID assigned by requestLogger
+func withCaller(r *http.Request) *http.Request {
+	// Forwarding headers are not trusted, as anyone can set them
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	caller := Caller{SourceIP: ip, RequestID: logging.RequestID(r.Context())}
+
+	return r.WithContext(WithCaller(r.Context(), caller))
+}
+
+// newMemoryAuditLog returns an audit log kept in memory only
+func newMemoryAuditLog() *storage.AuditLog {
+	l, _ := storage.NewAuditLog("") // Cannot fail without a file
+	return l
+}
+
+// SetAuditLog records administrative changes to l instead of memory
+func (h *Handler) SetAuditLog(l *storage.AuditLog) {
+	h.audit = l
+}
+
+// recordAudit records a change made by the caller of ctx
+// Failures are logged; the change itself has already been made.
+func (h *Handler) recordAudit(ctx context.Context, action, resource string, oldValue, newValue any) {
+	actor := anonymousActor
+	if key, ok := auth.FromContext(ctx); ok {
+		actor = key.Name
+	}
+	caller := callerFrom(ctx)
+
+	_, err := h.audit.Record(model.AuditRecord{
+		Action:    action,
+		Resource:  resource,
+		Actor:     actor,
+		SourceIP:  caller.SourceIP,
+		RequestID: caller.RequestID,
+		OldValue:  oldValue,
+		NewValue:  newValue,
+	})
+	if err != nil {
+		h.metrics.storageError(opRecordAudit)
+		slog.WarnContext(ctx, "Failed to record change in the audit log", "action", action, "error", err)
+	}
+}
+
+// ListAudit returns recorded administrative changes, newest first
+// Query parameters: from, to (RFC 3339), actor, action, limit, offset
+func (h *Handler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendError(w, errMethodNotAllowed)
+		return
+	}
+
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	records, total, err := h.audit.List(filter)
+	if err != nil {
+		h.metrics.storageError(opReadAudit)
+		slog.ErrorContext(r.Context(), "Failed to list audit log", "error", err)
+		sendError(w, errAuditRead)
+		return
+	}
+
+	response := model.AuditListResponse{
+		Records: records,
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// parseAuditFilter reads audit selection and pagination query parameters
+func parseAuditFilter(r *http.Request) (storage.AuditFilter, error) {
+	query := r.URL.Query()
+	filter := storage.AuditFilter{
+		Actor:  query.Get("actor"),
+		Action: query.Get("action"),
+	}
+
+	var err error
+	if filter.From, err = parseTimeParam(query.Get("from")); err != nil {
+		return filter, errBadParam("from")
+	}
+	if filter.To, err = parseTimeParam(query.Get("to")); err != nil {
+		return filter, errBadParam("to")
+	}
+
+	filter.Limit, filter.Offset, err = parsePage(r)
+
+	return filter, err
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"order-pack-calculator/internal/auth"
+	"order-pack-calculator/internal/model"
+	"strings"
+	"testing"
+)
+
+// listAudit returns the audit records selected by query
+func listAudit(t *testing.T, handler *Handler, query string) model.AuditListResponse {
+	t.Helper()
+
+	w := serveAPI(handler, http.MethodGet, "/audit"+query, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response model.AuditListResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return response
+}
+
+func TestAuditPackSizeChanges(t *testing.T) {
+	keys, _ := auth.ParseKeys("erp:admin:" + auth.HashKey("admin-key"))
+	handler := NewHandler([]int{250, 500})
+	handler.SetAPIKeys(keys)
+	r := handler.NewRouter()
+
+	req := httptest.NewRequest(http.MethodPut, APIPrefix+"/packs", strings.NewReader(`{"pack_sizes": [23, 31, 53]}`))
+	req.Header.Set(APIKeyHeader, "admin-key")
+	req.Header.Set(RequestIDHeader, "req-1")
+	req.RemoteAddr = "192.0.2.10:40000"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get(RequestIDHeader); got != "req-1" {
+		t.Errorf("Expected request ID req-1 to be returned, got %q", got)
+	}
+
+	// Failed changes are not recorded
+	req = httptest.NewRequest(http.MethodPut, APIPrefix+"/packs", strings.NewReader(`{"pack_sizes": [-1]}`))
+	req.Header.Set(APIKeyHeader, "admin-key")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodGet, APIPrefix+"/audit", nil)
+	req.Header.Set(APIKeyHeader, "admin-key")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var response model.AuditListResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Total != 1 {
+		t.Fatalf("Expected 1 audit record, got %d", response.Total)
+	}
+
+	rec := response.Records[0]
+	if rec.Action != AuditPackSizesUpdated || rec.Actor != "erp" || rec.SourceIP != "192.0.2.10" || rec.RequestID != "req-1" {
+		t.Errorf("Unexpected audit record: %+v", rec)
+	}
+	oldValue, _ := json.Marshal(rec.OldValue)
+	newValue, _ := json.Marshal(rec.NewValue)
+	if string(oldValue) != `{"pack_sizes":[250,500],"version":0}` || string(newValue) != `{"pack_sizes":[23,31,53],"version":1}` {
+		t.Errorf("Unexpected old and new values: %s, %s", oldValue, newValue)
+	}
+}
+
+func TestAuditWebhookChanges(t *testing.T) {
+	handler, _ := newWebhookHandler(t)
+
+	w := serveAPI(handler, http.MethodPost, "/webhooks",
+		`{"url": "https://erp.example.com/hooks", "events": ["pack_sizes.updated"], "secret": "s3cret"}`)
+	var created model.WebhookSubscription
+	json.NewDecoder(w.Body).Decode(&created)
+	serveAPI(handler, http.MethodDelete, "/webhooks/"+created.ID, "")
+
+	response := listAudit(t, handler, "")
+	if response.Total != 2 {
+		t.Fatalf("Expected 2 audit records, got %d", response.Total)
+	}
+
+	deleted, added := response.Records[0], response.Records[1]
+	if added.Action != AuditWebhookCreated || added.Resource != created.ID || added.Actor != anonymousActor || added.NewValue == nil {
+		t.Errorf("Unexpected creation record: %+v", added)
+	}
+	if deleted.Action != AuditWebhookDeleted || deleted.Resource != created.ID || deleted.OldValue == nil || deleted.NewValue != nil {
+		t.Errorf("Unexpected deletion record: %+v", deleted)
+	}
+	if deleted.RequestID == "" || deleted.RequestID == added.RequestID {
+		t.Errorf("Expected distinct generated request IDs, got %q and %q", added.RequestID, deleted.RequestID)
+	}
+
+	body, _ := json.Marshal(response)
+	if strings.Contains(string(body), "s3cret") {
+		t.Error("Expected webhook secrets not to be recorded")
+	}
+
+	if response := listAudit(t, handler, "?action="+AuditWebhookDeleted); response.Total != 1 {
+		t.Errorf("Expected 1 deletion, got %d", response.Total)
+	}
+	if response := listAudit(t, handler, "?actor=erp"); response.Total != 0 {
+		t.Errorf("Expected no changes by erp, got %d", response.Total)
+	}
+}
+
+func TestListAuditInvalidParams(t *testing.T) {
+	handler := NewHandler([]int{250, 500})
+
+	for _, query := range []string{"?from=yesterday", "?limit=0", "?offset=-1"} {
+		w := serveAPI(handler, http.MethodGet, "/audit"+query, "")
+		if problem := decodeProblem(t, w, http.StatusBadRequest); problem.Code != CodeInvalidQueryParameter {
+			t.Errorf("%s: expected code %s, got %s", query, CodeInvalidQueryParameter, problem.Code)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"order-pack-calculator/internal/auth"
+	"strings"
+	"testing"
+)
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	keys, err := auth.ParseKeys("erp:admin:" + auth.HashKey("admin-key") + ",dashboard:read:" + auth.HashKey("read-key"))
+	if err != nil {
+		t.Fatalf("ParseKeys failed: %v", err)
+	}
+	handler := NewHandler([]int{250, 500})
+	handler.SetAPIKeys(keys)
+	r := handler.NewRouter()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		key        string
+		wantStatus int
+		wantCode   string
+	}{
+		{"no key", http.MethodGet, "/packs", "", "", http.StatusUnauthorized, CodeUnauthorized},
+		{"unknown key", http.MethodGet, "/packs", "", "other-key", http.StatusUnauthorized, CodeUnauthorized},
+		{"read views", http.MethodGet, "/packs", "", "read-key", http.StatusOK, ""},
+		{"read calculates", http.MethodPost, "/calculate", `{"order_quantity": 251}`, "read-key", http.StatusOK, ""},
+		{"read cannot update", http.MethodPut, "/packs", `{"pack_sizes": [100]}`, "read-key", http.StatusForbidden, CodeForbidden},
+		{"read cannot manage webhooks", http.MethodGet, "/webhooks", "", "read-key", http.StatusForbidden, CodeForbidden},
+		{"admin updates", http.MethodPut, "/packs", `{"pack_sizes": [100]}`, "admin-key", http.StatusOK, ""},
+		{"admin calculates", http.MethodPost, "/calculate", `{"order_quantity": 251}`, "admin-key", http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, APIPrefix+tt.path, strings.NewReader(tt.body))
+			if tt.key != "" {
+				req.Header.Set(APIKeyHeader, tt.key)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if tt.wantCode == "" {
+				if w.Code != tt.wantStatus {
+					t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+				}
+				return
+			}
+			if problem := decodeProblem(t, w, tt.wantStatus); problem.Code != tt.wantCode {
+				t.Errorf("Expected code %s, got %s", tt.wantCode, problem.Code)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("Expected a WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestAPIKeyInQueryOnlyForEvents(t *testing.T) {
+	keys, _ := auth.ParseKeys("dashboard:read:" + auth.HashKey("read-key"))
+	handler := NewHandler([]int{250, 500})
+	handler.SetAPIKeys(keys)
+	r := handler.NewRouter()
+
+	// Other endpoints ignore the query parameter, so keys do not end up in
+	// access logs by accident
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, APIPrefix+"/packs?api_key=read-key", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+
+	// The event stream accepts it, as EventSource cannot send headers
+	req := httptest.NewRequest(http.MethodGet, APIPrefix+"/packs/events?api_key=read-key", nil)
+	ctx, cancel := context.WithCancel(req.Context())
+	cancel()
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req.WithContext(ctx))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestAPIKeysDisabled(t *testing.T) {
+	r := NewHandler([]int{250, 500}).NewRouter()
+
+	req := httptest.NewRequest(http.MethodPut, APIPrefix+"/packs", strings.NewReader(`{"pack_sizes": [100]}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 without configured keys, got %d", w.Code)
+	}
+}
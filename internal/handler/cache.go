@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"container/list"
+	"order-pack-calculator/internal/model"
+	"sync"
+)
+
+// Default result cache limits
+const (
+	DefaultCacheEntries = 10000
+	DefaultCacheBytes   = 16 << 20
+)
+
+// Approximate memory used by a cache entry: the entry with its list element
+// and map slot, plus each pack in the result
+const (
+	cacheEntryBytes = 256
+	cachePackBytes  = 16
+)
+
+// cacheKey identifies a calculation result
+type cacheKey struct {
+	version int64 // Pack set version
+	qty     int
+}
+
+// cacheEntry is a cached calculation result
+type cacheEntry struct {
+	key      cacheKey
+	response model.CalculateResponse
+	size     int64
+}
+
+// resultCache is an LRU cache of calculation results for the current pack
+// set, bounded by entry count and approximate memory. A nil cache caches
+// nothing.
+type resultCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int64
+	version    int64 // Only results for this pack set version are cached
+	entries    map[cacheKey]*list.Element
+	order      *list.List // Most recently used first
+	bytes      int64
+	hits       int64
+	misses     int64
+	evictions  int64
+}
+
+// newResultCache creates a cache for results of the given pack set version
+func newResultCache(maxEntries int, maxBytes int64, version int64) *resultCache {
+	return &resultCache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		version:    version,
+		entries:    make(map[cacheKey]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// get returns the cached result for an order, if any
+func (c *resultCache) get(version int64, qty int) (model.CalculateResponse, bool) {
+	if c == nil {
+		return model.CalculateResponse{}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[cacheKey{version: version, qty: qty}]
+	if !ok {
+		c.misses++
+		return model.CalculateResponse{}, false
+	}
+
+	c.hits++
+	c.order.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).response, true
+}
+
+// put caches a result, evicting the least recently used ones over the
+// limits. Results for other than the current pack set version are dropped,
+// as are results too large to ever fit.
+func (c *resultCache) put(version int64, response model.CalculateResponse) {
+	if c == nil {
+		return
+	}
+
+	entry := &cacheEntry{
+		key:      cacheKey{version: version, qty: response.OrderQuantity},
+		response: response,
+		size:     int64(cacheEntryBytes + len(response.Packs)*cachePackBytes),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if version != c.version || entry.size > c.maxBytes {
+		return
+	}
+	if _, ok := c.entries[entry.key]; ok {
+		return
+	}
+
+	c.entries[entry.key] = c.order.PushFront(entry)
+	c.bytes += entry.size
+
+	for len(c.entries) > c.maxEntries || c.bytes > c.maxBytes {
+		c.remove(c.order.Back())
+		c.evictions++
+	}
+}
+
+// reset drops all results and starts caching a new pack set version
+func (c *resultCache) reset(version int64) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version = version
+	c.entries = make(map[cacheKey]*list.Element)
+	c.order.Init()
+	c.bytes = 0
+}
+
+// stats returns the cache counters
+func (c *resultCache) stats() model.CacheStats {
+	if c == nil {
+		return model.CacheStats{}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return model.CacheStats{
+		Enabled:    true,
+		Hits:       c.hits,
+		Misses:     c.misses,
+		Evictions:  c.evictions,
+		Entries:    len(c.entries),
+		Bytes:      c.bytes,
+		MaxEntries: c.maxEntries,
+		MaxBytes:   c.maxBytes,
+	}
+}
+
+// remove drops an entry; the caller must hold c.mu
+func (c *resultCache) remove(elem *list.Element) {
+	entry := c.order.Remove(elem).(*cacheEntry)
+	delete(c.entries, entry.key)
+	c.bytes -= entry.size
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"order-pack-calculator/internal/model"
+	"testing"
+)
+
+// cachedResponse builds a result with the given number of pack sizes used
+func cachedResponse(qty, packs int) model.CalculateResponse {
+	response := model.CalculateResponse{OrderQuantity: qty, Packs: []model.PackBreakdown{}}
+	for i := 0; i < packs; i++ {
+		response.Packs = append(response.Packs, model.PackBreakdown{Size: i + 1, Quantity: 1})
+	}
+	return response
+}
+
+func TestResultCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newResultCache(2, DefaultCacheBytes, 1)
+
+	cache.put(1, cachedResponse(10, 1))
+	cache.put(1, cachedResponse(20, 1))
+	cache.get(1, 10) // 20 is now least recently used
+	cache.put(1, cachedResponse(30, 1))
+
+	if _, ok := cache.get(1, 20); ok {
+		t.Error("Expected 20 to be evicted")
+	}
+	for _, qty := range []int{10, 30} {
+		if _, ok := cache.get(1, qty); !ok {
+			t.Errorf("Expected %d to be cached", qty)
+		}
+	}
+
+	stats := cache.stats()
+	if stats.Entries != 2 || stats.Evictions != 1 || stats.Hits != 3 || stats.Misses != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestResultCacheMemoryLimit(t *testing.T) {
+	// Room for two single-pack results but not three
+	cache := newResultCache(100, 2*(cacheEntryBytes+cachePackBytes), 1)
+
+	for _, qty := range []int{10, 20, 30} {
+		cache.put(1, cachedResponse(qty, 1))
+	}
+	if stats := cache.stats(); stats.Entries != 2 || stats.Bytes > stats.MaxBytes {
+		t.Errorf("Expected 2 entries within the memory limit, got %+v", stats)
+	}
+
+	// Results larger than the whole cache are not stored
+	cache.put(1, cachedResponse(40, 100))
+	if _, ok := cache.get(1, 40); ok {
+		t.Error("Expected oversized result not to be cached")
+	}
+}
+
+func TestResultCacheVersions(t *testing.T) {
+	cache := newResultCache(10, DefaultCacheBytes, 1)
+	cache.put(1, cachedResponse(10, 1))
+
+	cache.reset(2)
+	if _, ok := cache.get(1, 10); ok {
+		t.Error("Expected reset to drop results of the previous version")
+	}
+
+	// Late results calculated against an old pack set are dropped
+	cache.put(1, cachedResponse(10, 1))
+	if stats := cache.stats(); stats.Entries != 0 {
+		t.Errorf("Expected no entries, got %d", stats.Entries)
+	}
+}
+
+func TestCalculatePacksUsesCache(t *testing.T) {
+	handler := NewHandler([]int{250, 500, 1000})
+	handler.EnableResultCache(DefaultCacheEntries, DefaultCacheBytes)
+
+	calculate := func(qty int) model.CalculateResponse {
+		body, _ := json.Marshal(model.CalculateRequest{OrderQuantity: qty})
+		req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.CalculatePacks(w, req)
+
+		var response model.CalculateResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return response
+	}
+
+	calculate(251)
+	if response := calculate(251); response.TotalItems != 500 {
+		t.Errorf("Expected total items 500, got %d", response.TotalItems)
+	}
+
+	// Updating the pack sizes invalidates cached results
+	body, _ := json.Marshal(model.PackSizesRequest{PackSizes: []int{23, 31, 53}})
+	req := httptest.NewRequest(http.MethodPut, "/api/packs", bytes.NewReader(body))
+	handler.UpdatePackSizes(httptest.NewRecorder(), req)
+
+	if response := calculate(251); response.TotalItems == 500 {
+		t.Errorf("Expected a result for the new pack sizes, got %+v", response)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/metrics", nil)
+	w := httptest.NewRecorder()
+	handler.GetMetrics(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+
+	var metrics model.MetricsResponse
+	if err := json.NewDecoder(w.Body).Decode(&metrics); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !metrics.Cache.Enabled || metrics.Cache.Hits != 1 || metrics.Cache.Misses != 2 || metrics.Cache.Entries != 1 {
+		t.Errorf("Unexpected cache metrics: %+v", metrics.Cache)
+	}
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"order-pack-calculator/internal/calculator"
+	"order-pack-calculator/internal/model"
+	"strconv"
+	"strings"
+)
+
+// CalculatePacksCSV calculates packs for a CSV of orders
+// Input rows are order_id,quantity (a header row is optional). The response
+// streams one output row per input row as it is calculated, so files of any
+// size can be processed: order_id, quantity, one column per pack size,
+// total_items, excess, total_packs and error. Rows that cannot be
+// calculated carry a message in the error column instead of results.
+// Every row is an order of its own, so each calculated row is recorded in
+// the order history and published as an order.calculated event. Uploads
+// stop with an error row after Limits.MaxCSVRows orders.
+func (h *Handler) CalculatePacksCSV(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendError(w, errMethodNotAllowed)
+		return
+	}
+
+	// Use one pack sizes snapshot for the whole file so columns stay stable
+	set := h.snapshot()
+	columns := calculator.UniqueDescending(set.sizes)
+
+	reader := csv.NewReader(r.Body)
+	reader.FieldsPerRecord = -1 // Report wrong field counts per row
+	reader.ReuseRecord = true
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="packs.csv"`)
+	writer := csv.NewWriter(w)
+
+	// Keep reading the body while rows are written; HTTP/1 would otherwise
+	// discard the unread rest of the upload once the first row is flushed
+	rc := http.NewResponseController(w)
+	rc.EnableFullDuplex()
+
+	// Large files may take longer than the server's read and write
+	// timeouts, so both are extended for every row; a client that stalls
+	// is still cut off
+	readTimeout, writeTimeout := serverTimeouts(r)
+
+	header := []string{"order_id", "quantity"}
+	for _, size := range columns {
+		header = append(header, "pack_"+strconv.Itoa(size))
+	}
+	header = append(header, "total_items", "excess", "total_packs", "error")
+	writer.Write(header)
+
+	orders := 0
+	for line := 1; ; line++ {
+		// Stop calculating once the client has gone away
+		if r.Context().Err() != nil {
+			break
+		}
+
+		extendDeadline(rc.SetReadDeadline, readTimeout)
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if err != nil && !errors.As(err, &parseErr) {
+			// The body can no longer be read; report it in the output
+			slog.WarnContext(r.Context(), "Failed to read CSV body", "error", err)
+			row := make([]string, len(header))
+			row[len(row)-1] = "Failed to read request body"
+			writer.Write(row)
+			break
+		}
+
+		order, err := parseCSVOrder(record, err, line)
+		if err == errCSVHeader {
+			continue
+		}
+
+		row := make([]string, len(header))
+		orders++
+		if set.limits.MaxCSVRows > 0 && orders > set.limits.MaxCSVRows {
+			row[len(row)-1] = fmt.Sprintf("Uploads must not exceed %d orders", set.limits.MaxCSVRows)
+			writer.Write(row)
+			break
+		}
+		row[0] = order.id
+		row[1] = order.quantity
+		if err != nil {
+			row[len(row)-1] = err.Error()
+		} else {
+			req := model.CalculateRequest{OrderQuantity: order.qty, ClientOrderID: order.id}
+			response, err := h.calculateOrder(r.Context(), req, set)
+			if err != nil {
+				row[len(row)-1] = calculationError(err, set.limits).message
+			} else {
+				fillResults(row, response, columns)
+			}
+		}
+
+		writer.Write(row)
+		extendDeadline(rc.SetWriteDeadline, writeTimeout)
+		writer.Flush()
+		rc.Flush()
+	}
+
+	extendDeadline(rc.SetWriteDeadline, writeTimeout)
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		slog.WarnContext(r.Context(), "Failed to write CSV response", "error", err)
+	}
+}
+
+// errCSVHeader marks the optional header row
+var errCSVHeader = errors.New("header row")
+
+// csvOrder is one order read from a CSV row
+type csvOrder struct {
+	id       string
+	quantity string // As written in the input
+	qty      int
+}
+
+// parseCSVOrder validates a CSV record read at the given line
+// Returns errCSVHeader for a header row on the first line
+func parseCSVOrder(record []string, readErr error, line int) (csvOrder, error) {
+	if readErr != nil {
+		return csvOrder{}, readErr
+	}
+
+	var order csvOrder
+	if len(record) > 0 {
+		order.id = strings.TrimSpace(record[0])
+	}
+	if len(record) != 2 {
+		return order, errors.New("Expected 2 fields: order_id,quantity")
+	}
+	order.quantity = strings.TrimSpace(record[1])
+
+	qty, err := strconv.Atoi(order.quantity)
+	if err != nil {
+		if line == 1 && strings.EqualFold(order.quantity, "quantity") {
+			return order, errCSVHeader
+		}
+		return order, errors.New("Quantity must be an integer")
+	}
+	if qty < 0 {
+		return order, errors.New("Order quantity must be a non-negative integer")
+	}
+	order.qty = qty
+
+	return order, nil
+}
+
+// fillResults writes a calculation into an output row's result columns
+func fillResults(row []string, response model.CalculateResponse, columns []int) {
+	counts := make(map[int]int, len(response.Packs))
+	for _, pack := range response.Packs {
+		counts[pack.Size] = pack.Quantity
+	}
+
+	for i, size := range columns {
+		row[2+i] = strconv.Itoa(counts[size])
+	}
+
+	n := 2 + len(columns)
+	row[n] = strconv.Itoa(response.TotalItems)
+	row[n+1] = strconv.Itoa(response.TotalItems - response.OrderQuantity)
+	row[n+2] = strconv.Itoa(response.TotalPacks)
+}
@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"order-pack-calculator/internal/storage"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalculatePacksCSV(t *testing.T) {
+	handler := NewHandler([]int{250, 500, 1000})
+
+	body := "order_id,quantity\nPO-1,251\nPO-2,1001\nPO-3,abc\nPO-4,-1\nPO-5\nPO-6,0\n"
+	req := httptest.NewRequest(http.MethodPost, "/api/calculate/csv", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.CalculatePacksCSV(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/csv" {
+		t.Errorf("Expected text/csv, got %q", ct)
+	}
+
+	reader := csv.NewReader(w.Body)
+	rows, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV response: %v", err)
+	}
+
+	want := [][]string{
+		{"order_id", "quantity", "pack_1000", "pack_500", "pack_250", "total_items", "excess", "total_packs", "error"},
+		{"PO-1", "251", "0", "1", "0", "500", "249", "1", ""},
+		{"PO-2", "1001", "1", "0", "1", "1250", "249", "2", ""},
+		{"PO-3", "abc", "", "", "", "", "", "", "Quantity must be an integer"},
+		{"PO-4", "-1", "", "", "", "", "", "", "Order quantity must be a non-negative integer"},
+		{"PO-5", "", "", "", "", "", "", "", "Expected 2 fields: order_id,quantity"},
+		{"PO-6", "0", "0", "0", "0", "0", "0", "0", ""},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Unexpected CSV output:\ngot  %v\nwant %v", rows, want)
+	}
+}
+
+func TestCalculatePacksCSVWithoutHeader(t *testing.T) {
+	handler := NewHandler([]int{250, 500})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/calculate/csv", strings.NewReader("A,1\n"))
+	w := httptest.NewRecorder()
+	handler.CalculatePacksCSV(w, req)
+
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV response: %v", err)
+	}
+	if len(rows) != 2 || rows[1][0] != "A" || rows[1][4] != "250" {
+		t.Errorf("Unexpected CSV output: %v", rows)
+	}
+}
+
+func TestCalculatePacksCSVRecordsOrders(t *testing.T) {
+	handler := newHistoryHandler(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/calculate/csv", strings.NewReader("PO-1,251\nPO-2,x\n"))
+	w := httptest.NewRecorder()
+	handler.CalculatePacksCSV(w, req)
+
+	_, total, err := handler.history.List(storage.OrderFilter{})
+	if err != nil {
+		t.Fatalf("Failed to list orders: %v", err)
+	}
+	if total != 1 {
+		t.Errorf("Expected 1 recorded order, got %d", total)
+	}
+}
+
+func TestCalculatePacksCSVRowLimit(t *testing.T) {
+	handler := newHistoryHandler(t)
+	handler.SetLimits(Limits{MaxCSVRows: 2})
+
+	body := "order_id,quantity\nPO-1,251\nPO-2,x\nPO-3,501\nPO-4,1\n"
+	req := httptest.NewRequest(http.MethodPost, "/api/calculate/csv", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.CalculatePacksCSV(w, req)
+
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV response: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("Expected header, 2 orders and an error row, got %v", rows)
+	}
+	if rows[1][0] != "PO-1" || rows[2][0] != "PO-2" {
+		t.Errorf("Expected the first 2 orders, got %v", rows[1:3])
+	}
+	if last := rows[3]; last[0] != "" || last[len(last)-1] != "Uploads must not exceed 2 orders" {
+		t.Errorf("Unexpected last row: %v", last)
+	}
+
+	if _, total, _ := handler.history.List(storage.OrderFilter{}); total != 1 {
+		t.Errorf("Expected 1 recorded order, got %d", total)
+	}
+}
+
+func TestCalculatePacksCSVLargeUpload(t *testing.T) {
+	server := httptest.NewServer(NewHandler([]int{250, 500}).NewRouter())
+	defer server.Close()
+
+	// Large enough for rows to be flushed while the body is still being read
+	const orders = 2000
+	var body strings.Builder
+	body.WriteString("order_id,quantity\n")
+	for i := 1; i <= orders; i++ {
+		fmt.Fprintf(&body, "PO-%d,%d\n", i, i)
+	}
+
+	resp, err := http.Post(server.URL+APIPrefix+"/calculate/csv", "text/csv", strings.NewReader(body.String()))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	rows, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV response: %v", err)
+	}
+	if len(rows) != orders+1 {
+		t.Fatalf("Expected %d rows, got %d", orders+1, len(rows))
+	}
+	for i, row := range rows[1:] {
+		if row[0] != fmt.Sprintf("PO-%d", i+1) || row[len(row)-1] != "" {
+			t.Fatalf("Unexpected row %d: %v", i+1, row)
+		}
+	}
+}
+
+func TestCalculatePacksCSVStreams(t *testing.T) {
+	server := httptest.NewServer(NewHandler([]int{250, 500}).NewRouter())
+	defer server.Close()
+
+	// Rows come back while the client is still uploading the rest
+	body, upload := io.Pipe()
+	defer upload.Close()
+	go upload.Write([]byte("order_id,quantity\nPO-1,251\n"))
+
+	resp, err := http.Post(server.URL+APIPrefix+"/calculate/csv", "text/csv", body)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	rows := make(chan []string)
+	go func() {
+		reader := csv.NewReader(resp.Body)
+		for {
+			row, err := reader.Read()
+			if err != nil {
+				close(rows)
+				return
+			}
+			rows <- row
+		}
+	}()
+
+	for _, want := range []string{"order_id", "PO-1"} {
+		select {
+		case row := <-rows:
+			if row[0] != want {
+				t.Fatalf("Expected row %s, got %v", want, row)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timed out waiting for row %s before the upload finished", want)
+		}
+	}
+
+	upload.Write([]byte("PO-2,501\n"))
+	upload.Close()
+	if row := <-rows; len(row) == 0 || row[0] != "PO-2" {
+		t.Errorf("Expected row PO-2, got %v", row)
+	}
+}
+
+func TestCalculatePacksCSVOutlivesServerTimeouts(t *testing.T) {
+	server := httptest.NewUnstartedServer(NewHandler([]int{250, 500}).NewRouter())
+	server.Config.ReadTimeout = 200 * time.Millisecond
+	server.Config.WriteTimeout = 200 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	// Upload for longer than both timeouts allow, but with steady progress
+	body, upload := io.Pipe()
+	go func() {
+		for i := 1; i <= 5; i++ {
+			time.Sleep(100 * time.Millisecond)
+			fmt.Fprintf(upload, "PO-%d,%d\n", i, i)
+		}
+		upload.Close()
+	}()
+
+	resp, err := http.Post(server.URL+APIPrefix+"/calculate/csv", "text/csv", body)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	rows, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV response: %v", err)
+	}
+	if len(rows) != 6 || rows[5][0] != "PO-5" || rows[5][len(rows[5])-1] != "" {
+		t.Errorf("Expected all 5 orders calculated, got %v", rows)
+	}
+}
+
+func TestCalculatePacksCSVStalledUpload(t *testing.T) {
+	server := httptest.NewUnstartedServer(NewHandler([]int{250, 500}).NewRouter())
+	server.Config.ReadTimeout = 100 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	// One row, then nothing until the test ends
+	body, upload := io.Pipe()
+	defer upload.Close()
+	go fmt.Fprint(upload, "PO-1,1\n")
+
+	resp, err := http.Post(server.URL+APIPrefix+"/calculate/csv", "text/csv", body)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	done := make(chan [][]string, 1)
+	go func() {
+		rows, _ := csv.NewReader(resp.Body).ReadAll()
+		done <- rows
+	}()
+
+	select {
+	case rows := <-done:
+		last := rows[len(rows)-1]
+		if len(rows) != 3 || rows[1][0] != "PO-1" || last[len(last)-1] != "Failed to read request body" {
+			t.Errorf("Expected PO-1 and a read error, got %v", rows)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stalled upload was not cut off")
+	}
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// MaxBodyBytes is the largest JSON request body accepted
+const MaxBodyBytes = 1 << 20
+
+// Error codes of request decoding
+const (
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeBodyTooLarge         = "body_too_large"
+	CodeUnknownField         = "unknown_field"
+	CodeInvalidType          = "invalid_type"
+	CodeMissingField         = "missing_field"
+)
+
+var (
+	errUnsupportedMediaType = newProblem(http.StatusUnsupportedMediaType, CodeUnsupportedMediaType,
+		"Content-Type must be application/json")
+	errBodyTooLarge = newProblem(http.StatusRequestEntityTooLarge, CodeBodyTooLarge,
+		fmt.Sprintf("Request body must not exceed %d bytes", MaxBodyBytes))
+	errTrailingData = newProblem(http.StatusBadRequest, CodeInvalidBody,
+		"Request body must contain a single JSON object")
+)
+
+// decodeJSON strictly decodes a JSON request body into v
+// The body must be a single JSON object of at most MaxBodyBytes, sent as
+// application/json (or without a Content-Type), with no fields unknown to v
+// and a non-null value for each of the required fields.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any, required ...string) error {
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil || (mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")) {
+			return errUnsupportedMediaType
+		}
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodyBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return errBodyTooLarge
+		}
+		return errInvalidBody
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return decodeError(err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errTrailingData
+	}
+
+	// Tell a missing field apart from one sent as its zero value
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return errInvalidBody
+	}
+	for _, name := range required {
+		if raw, ok := fields[name]; !ok || string(raw) == "null" {
+			return newFieldProblem(http.StatusBadRequest, CodeMissingField, name,
+				fmt.Sprintf("Missing required field: %s", name))
+		}
+	}
+
+	return nil
+}
+
+// decodeError converts a JSON decoding error to a problem error
+func decodeError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return newFieldProblem(http.StatusBadRequest, CodeInvalidType, typeErr.Field,
+			fmt.Sprintf("Invalid type for field %s: expected %s", typeErr.Field, typeErr.Type))
+	}
+
+	// encoding/json has no error type for unknown fields
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		name = strings.Trim(name, `"`)
+		return newFieldProblem(http.StatusBadRequest, CodeUnknownField, name,
+			fmt.Sprintf("Unknown field: %s", name))
+	}
+
+	return errInvalidBody
+}
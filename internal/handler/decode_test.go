@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCalculatePacksStrictDecoding(t *testing.T) {
+	handler := NewHandler([]int{250, 500})
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantCode    string
+		wantField   string
+	}{
+		{
+			name:       "valid without content type",
+			body:       `{"order_quantity": 251}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "valid with charset",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"order_quantity": 0}`,
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:       "unknown field",
+			body:       `{"orderQuantity": 251}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   CodeUnknownField,
+			wantField:  "orderQuantity",
+		},
+		{
+			name:       "missing order quantity",
+			body:       `{"sku": "A1"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   CodeMissingField,
+			wantField:  "order_quantity",
+		},
+		{
+			name:       "null order quantity",
+			body:       `{"order_quantity": null}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   CodeMissingField,
+			wantField:  "order_quantity",
+		},
+		{
+			name:       "wrong type",
+			body:       `{"order_quantity": "251"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   CodeInvalidType,
+			wantField:  "order_quantity",
+		},
+		{
+			name:       "trailing data",
+			body:       `{"order_quantity": 251} {"order_quantity": 1}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   CodeInvalidBody,
+		},
+		{
+			name:       "not an object",
+			body:       `[251]`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   CodeInvalidBody,
+		},
+		{
+			name:        "wrong content type",
+			contentType: "text/plain",
+			body:        `{"order_quantity": 251}`,
+			wantStatus:  http.StatusUnsupportedMediaType,
+			wantCode:    CodeUnsupportedMediaType,
+		},
+		{
+			name:       "body too large",
+			body:       `{"order_quantity": 251, "sku": "` + strings.Repeat("x", MaxBodyBytes) + `"}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantCode:   CodeBodyTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/calculate", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+
+			handler.CalculatePacks(w, req)
+
+			if tt.wantStatus == http.StatusOK {
+				if w.Code != http.StatusOK {
+					t.Errorf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+				}
+				return
+			}
+
+			problem := decodeProblem(t, w, tt.wantStatus)
+			if problem.Code != tt.wantCode || problem.Field != tt.wantField {
+				t.Errorf("Expected code %q for %q, got %q for %q", tt.wantCode, tt.wantField, problem.Code, problem.Field)
+			}
+		})
+	}
+}
+
+func TestUpdatePackSizesMissingField(t *testing.T) {
+	handler := NewHandler([]int{250, 500})
+
+	req := httptest.NewRequest(http.MethodPut, "/api/packs", strings.NewReader(`{"packSizes": [100]}`))
+	w := httptest.NewRecorder()
+
+	handler.UpdatePackSizes(w, req)
+
+	problem := decodeProblem(t, w, http.StatusBadRequest)
+	if problem.Code != CodeUnknownField || problem.Field != "packSizes" {
+		t.Errorf("Expected code %s for packSizes, got %s for %s", CodeUnknownField, problem.Code, problem.Field)
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/api/packs", strings.NewReader(`{}`))
+	w = httptest.NewRecorder()
+
+	handler.UpdatePackSizes(w, req)
+
+	problem = decodeProblem(t, w, http.StatusBadRequest)
+	if problem.Code != CodeMissingField || problem.Field != "pack_sizes" {
+		t.Errorf("Expected code %s for pack_sizes, got %s for %s", CodeMissingField, problem.Code, problem.Field)
+	}
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"order-pack-calculator/internal/model"
+)
+
+// Error codes sent with problem responses, also used by the gRPC service
+// They are part of the API and must not change once released.
+const (
+	CodeInvalidBody           = "invalid_body"
+	CodeUnauthorized          = "unauthorized"
+	CodeForbidden             = "forbidden"
+	CodeMethodNotAllowed      = "method_not_allowed"
+	CodeNotFound              = "not_found"
+	CodeEmptyPackSizes        = "empty_pack_sizes"
+	CodeInvalidPackSizes      = "invalid_pack_sizes"
+	CodeInvalidPackSize       = "invalid_pack_size"
+	CodeInvalidOrderQuantity  = "invalid_order_quantity"
+	CodeQuantityTooLarge      = "order_quantity_too_large"
+	CodeMemoryBudgetExceeded  = "memory_budget_exceeded"
+	CodeCalculationTimeout    = "calculation_timeout"
+	CodeCalculationCancelled  = "calculation_cancelled"
+	CodeNoPackSizes           = "no_pack_sizes"
+	CodeHistoryDisabled       = "order_history_disabled"
+	CodeOrderNotFound         = "order_not_found"
+	CodeInvalidQueryParameter = "invalid_query_parameter"
+	CodeInvalidWebhook        = "invalid_webhook"
+	CodeInvalidURL            = "invalid_url"
+	CodeInvalidEvent          = "invalid_event"
+	CodeMissingSecret         = "missing_secret"
+	CodeWebhooksDisabled      = "webhooks_disabled"
+	CodeWebhookNotFound       = "webhook_not_found"
+	CodeDeadLetterNotFound    = "dead_letter_not_found"
+	CodeWebhookRemoved        = "webhook_removed"
+	CodeInternalError         = "internal_error"
+)
+
+// problemContentType is the media type of problem responses (RFC 7807)
+const problemContentType = "application/problem+json"
+
+// problemError is an error reported to the client as a problem response
+type problemError struct {
+	status  int
+	code    string
+	field   string
+	message string
+	errors  []model.FieldError
+}
+
+func (e *problemError) Error() string {
+	return e.message
+}
+
+// newProblem creates a problem error without a field
+func newProblem(status int, code, message string) *problemError {
+	return &problemError{status: status, code: code, message: message}
+}
+
+// newFieldProblem creates a problem error about a request field
+func newFieldProblem(status int, code, field, message string) *problemError {
+	return &problemError{status: status, code: code, field: field, message: message}
+}
+
+var (
+	errInvalidBody      = newProblem(http.StatusBadRequest, CodeInvalidBody, "Invalid request body")
+	errUnauthorized     = newProblem(http.StatusUnauthorized, CodeUnauthorized, "Missing or invalid API key")
+	errForbidden        = newProblem(http.StatusForbidden, CodeForbidden, "The API key does not allow this operation")
+	errMethodNotAllowed = newProblem(http.StatusMethodNotAllowed, CodeMethodNotAllowed, "Method not allowed")
+	errNotFound         = newProblem(http.StatusNotFound, CodeNotFound, "No such API endpoint")
+	errHistoryDisabled  = newProblem(http.StatusNotFound, CodeHistoryDisabled, "Order history is not enabled")
+	errOrderNotFound    = newProblem(http.StatusNotFound, CodeOrderNotFound, "Order not found")
+	errHistoryRead      = newProblem(http.StatusInternalServerError, CodeInternalError, "Failed to read order history")
+	errAuditRead        = newProblem(http.StatusInternalServerError, CodeInternalError, "Failed to read audit log")
+	errPackSizesSave    = newProblem(http.StatusInternalServerError, CodeInternalError, "Failed to save pack sizes")
+	errWebhooksDisabled = newProblem(http.StatusNotFound, CodeWebhooksDisabled, "Webhooks are not enabled")
+	errWebhookNotFound  = newProblem(http.StatusNotFound, CodeWebhookNotFound, "Webhook not found")
+	errWebhookSave      = newProblem(http.StatusInternalServerError, CodeInternalError, "Failed to save webhooks")
+	errWebhookRemoved   = newProblem(http.StatusConflict, CodeWebhookRemoved,
+		"The webhook of this dead letter has been removed")
+	errDeadLetterNotFound = newProblem(http.StatusNotFound, CodeDeadLetterNotFound, "Dead letter not found")
+	errNoSolver           = newProblem(http.StatusInternalServerError, CodeNoPackSizes, "No valid pack sizes configured")
+	errNegativeQuantity   = newFieldProblem(http.StatusBadRequest, CodeInvalidOrderQuantity, "order_quantity",
+		"Order quantity must be a non-negative integer")
+)
+
+// errBadParam reports an invalid query parameter
+func errBadParam(name string) error {
+	return newFieldProblem(http.StatusBadRequest, CodeInvalidQueryParameter, name,
+		fmt.Sprintf("Invalid query parameter: %s", name))
+}
+
+// MethodNotAllowed responds with a 405 problem
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	sendError(w, errMethodNotAllowed)
+}
+
+// NotFound responds with a 404 problem
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	sendError(w, errNotFound)
+}
+
+// Problem converts an error returned by the handler to a problem response
+// Errors other than problem errors are logged and reported as 500.
+func Problem(err error) model.ErrorResponse {
+	problem, ok := err.(*problemError)
+	if !ok {
+		slog.Error("Unexpected error", "error", err)
+		problem = newProblem(http.StatusInternalServerError, CodeInternalError, "Internal server error")
+	}
+
+	return model.ErrorResponse{
+		Type:   "about:blank",
+		Title:  http.StatusText(problem.status),
+		Status: problem.status,
+		Detail: problem.message,
+		Error:  problem.message,
+		Code:   problem.code,
+		Field:  problem.field,
+		Errors: problem.errors,
+	}
+}
+
+// sendError sends an error as a problem response
+func sendError(w http.ResponseWriter, err error) {
+	response := Problem(err)
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(response.Status)
+	json.NewEncoder(w).Encode(response)
+}
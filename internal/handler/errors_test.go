@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"order-pack-calculator/internal/model"
+	"reflect"
+	"testing"
+)
+
+// decodeProblem checks a problem response and decodes its body
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder, wantStatus int) model.ErrorResponse {
+	t.Helper()
+
+	if w.Code != wantStatus {
+		t.Errorf("Expected status %d, got %d", wantStatus, w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != problemContentType {
+		t.Errorf("Expected content type %s, got %s", problemContentType, ct)
+	}
+
+	var problem model.ErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if problem.Status != wantStatus || problem.Title != http.StatusText(wantStatus) || problem.Type != "about:blank" {
+		t.Errorf("Unexpected problem fields: %+v", problem)
+	}
+	if problem.Error == "" || problem.Error != problem.Detail {
+		t.Errorf("Expected error to repeat detail, got %q and %q", problem.Error, problem.Detail)
+	}
+	return problem
+}
+
+func TestUpdatePackSizesProblem(t *testing.T) {
+	handler := NewHandler([]int{250, 500})
+
+	body, _ := json.Marshal(model.PackSizesRequest{PackSizes: []int{250, -100, 500, 0}})
+	req := httptest.NewRequest(http.MethodPut, "/api/packs", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.UpdatePackSizes(w, req)
+
+	problem := decodeProblem(t, w, http.StatusBadRequest)
+	if problem.Code != CodeInvalidPackSizes || problem.Field != "pack_sizes" {
+		t.Errorf("Expected code %s for pack_sizes, got %s for %s", CodeInvalidPackSizes, problem.Code, problem.Field)
+	}
+
+	want := []model.FieldError{
+		{Field: "pack_sizes[1]", Code: CodeInvalidPackSize, Message: "Pack size must be a positive integer"},
+		{Field: "pack_sizes[3]", Code: CodeInvalidPackSize, Message: "Pack size must be a positive integer"},
+	}
+	if !reflect.DeepEqual(problem.Errors, want) {
+		t.Errorf("Expected errors %v, got %v", want, problem.Errors)
+	}
+}
+
+func TestProblemCodes(t *testing.T) {
+	handler := NewHandler([]int{250, 500})
+	handler.SetLimits(Limits{MaxOrderQuantity: 1000})
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantCode   string
+		wantField  string
+	}{
+		{
+			name:       "malformed body",
+			method:     http.MethodPost,
+			body:       `{"order_quantity":`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   CodeInvalidBody,
+		},
+		{
+			name:       "negative quantity",
+			method:     http.MethodPost,
+			body:       `{"order_quantity": -1}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   CodeInvalidOrderQuantity,
+			wantField:  "order_quantity",
+		},
+		{
+			name:       "quantity above maximum",
+			method:     http.MethodPost,
+			body:       `{"order_quantity": 1001}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantCode:   CodeQuantityTooLarge,
+			wantField:  "order_quantity",
+		},
+		{
+			name:       "wrong method",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+			wantCode:   CodeMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/calculate", bytes.NewReader([]byte(tt.body)))
+			w := httptest.NewRecorder()
+
+			handler.CalculatePacks(w, req)
+
+			problem := decodeProblem(t, w, tt.wantStatus)
+			if problem.Code != tt.wantCode || problem.Field != tt.wantField {
+				t.Errorf("Expected code %q for %q, got %q for %q", tt.wantCode, tt.wantField, problem.Code, problem.Field)
+			}
+		})
+	}
+}
+
+func TestQueryParameterProblem(t *testing.T) {
+	handler := newHistoryHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/orders?limit=0", nil)
+	w := httptest.NewRecorder()
+
+	handler.ListOrders(w, req)
+
+	problem := decodeProblem(t, w, http.StatusBadRequest)
+	if problem.Code != CodeInvalidQueryParameter || problem.Field != "limit" {
+		t.Errorf("Expected code %s for limit, got %s for %s", CodeInvalidQueryParameter, problem.Code, problem.Field)
+	}
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"order-pack-calculator/internal/model"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// eventKeepAlive is how often an idle event stream sends a comment so that
+// proxies do not close the connection
+var eventKeepAlive = 15 * time.Second
+
+// packEvents fans pack size changes out to event stream subscribers
+// Subscribers only need the latest configuration, so a slow subscriber
+// skips superseded changes instead of holding up the others.
+type packEvents struct {
+	mu        sync.Mutex
+	subs      map[chan model.PackSizesResponse]struct{}
+	done      chan struct{} // Closed when the server shuts down
+	closeOnce sync.Once
+}
+
+// newPackEvents creates a fan-out without subscribers
+func newPackEvents() *packEvents {
+	return &packEvents{
+		subs: make(map[chan model.PackSizesResponse]struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+// close ends every event stream, open or opened later
+func (e *packEvents) close() {
+	e.closeOnce.Do(func() { close(e.done) })
+}
+
+// subscribe returns a channel receiving every later change
+func (e *packEvents) subscribe() chan model.PackSizesResponse {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ch := make(chan model.PackSizesResponse, 1)
+	e.subs[ch] = struct{}{}
+	return ch
+}
+
+// unsubscribe stops sending changes to ch
+func (e *packEvents) unsubscribe(ch chan model.PackSizesResponse) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.subs, ch)
+}
+
+// publish sends a change to every subscriber without blocking, replacing
+// any change a subscriber has not received yet
+func (e *packEvents) publish(sizes []int, version int64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	event := model.PackSizesResponse{PackSizes: append([]int{}, sizes...), Version: version}
+	for ch := range e.subs {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- event
+	}
+}
+
+// PackEvents streams the pack sizes as Server-Sent Events: the current
+// configuration first, then one event per change, each with the pack set
+// version as its ID. A client reconnecting with Last-Event-ID only gets the
+// current configuration again if it changed in the meantime.
+func (h *Handler) PackEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendError(w, errMethodNotAllowed)
+		return
+	}
+
+	// Subscribe before reading the current configuration so no change
+	// falls in between
+	events := h.events.subscribe()
+	defer h.events.unsubscribe(events)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // Stop nginx from buffering the stream
+	w.WriteHeader(http.StatusOK)
+
+	// Streams outlive the server's write timeout: after every write the
+	// deadline moves past the next keep-alive, so only a client that stops
+	// reading is cut off
+	rc := http.NewResponseController(w)
+	_, writeTimeout := serverTimeouts(r)
+	if writeTimeout > 0 {
+		writeTimeout += eventKeepAlive
+	}
+	lastID := r.Header.Get("Last-Event-ID")
+
+	sizes, version := h.PackSizes()
+	if strconv.FormatInt(version, 10) != lastID {
+		lastID = writePackEvent(w, model.PackSizesResponse{PackSizes: sizes, Version: version})
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+	extendDeadline(rc.SetWriteDeadline, writeTimeout)
+
+	keepAlive := time.NewTicker(eventKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.events.done:
+			// Shutting down; clients reconnect to another instance
+			return
+		case event := <-events:
+			if strconv.FormatInt(event.Version, 10) == lastID {
+				continue
+			}
+			lastID = writePackEvent(w, event)
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+		extendDeadline(rc.SetWriteDeadline, writeTimeout)
+	}
+}
+
+// writePackEvent writes a pack_sizes event and returns its ID
+func writePackEvent(w http.ResponseWriter, event model.PackSizesResponse) string {
+	id := strconv.FormatInt(event.Version, 10)
+	data, _ := json.Marshal(event)
+	fmt.Fprintf(w, "id: %s\nevent: pack_sizes\ndata: %s\n\n", id, data)
+	return id
+}
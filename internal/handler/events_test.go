@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"order-pack-calculator/internal/model"
+	"order-pack-calculator/internal/storage"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sseEvent is an event read from a Server-Sent Events stream
+type sseEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// openEventStream connects to the pack size event stream of a test server
+func openEventStream(t *testing.T, server *httptest.Server, lastEventID string) *bufio.Reader {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+APIPrefix+"/packs/events", nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open event stream: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected Content-Type text/event-stream, got %s", ct)
+	}
+
+	return bufio.NewReader(resp.Body)
+}
+
+// readEvent reads the next event, skipping comments, or fails after a second
+func readEvent(t *testing.T, r *bufio.Reader) (sseEvent, model.PackSizesResponse) {
+	t.Helper()
+
+	events := make(chan sseEvent, 1)
+	go func() {
+		var ev sseEvent
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				close(events)
+				return
+			}
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case line == "" && ev.event != "":
+				events <- ev
+				return
+			case strings.HasPrefix(line, "id: "):
+				ev.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				ev.event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				ev.data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+
+	select {
+	case ev, ok := <-events:
+		if !ok {
+			t.Fatal("Event stream ended")
+		}
+		var config model.PackSizesResponse
+		if err := json.Unmarshal([]byte(ev.data), &config); err != nil {
+			t.Fatalf("Failed to decode event data %q: %v", ev.data, err)
+		}
+		return ev, config
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for an event")
+		return sseEvent{}, model.PackSizesResponse{}
+	}
+}
+
+func TestPackEvents(t *testing.T) {
+	h := NewHandler([]int{250, 500})
+	server := httptest.NewServer(h.NewRouter())
+	t.Cleanup(server.Close) // Runs after the streams are closed
+
+	stream := openEventStream(t, server, "")
+
+	// The current configuration comes first
+	ev, config := readEvent(t, stream)
+	if ev.event != "pack_sizes" || ev.id != "0" {
+		t.Errorf("Expected pack_sizes event with ID 0, got %s with ID %s", ev.event, ev.id)
+	}
+	if len(config.PackSizes) != 2 || config.Version != 0 {
+		t.Errorf("Expected pack sizes [250 500] at version 0, got %v at version %d", config.PackSizes, config.Version)
+	}
+
+	// Then every change
+	if _, err := h.SetPackSizes(context.Background(), []int{23, 31, 53}); err != nil {
+		t.Fatalf("SetPackSizes failed: %v", err)
+	}
+	ev, config = readEvent(t, stream)
+	if ev.id != "1" || len(config.PackSizes) != 3 || config.Version != 1 {
+		t.Errorf("Expected pack sizes [23 31 53] with ID 1, got %v with ID %s", config.PackSizes, ev.id)
+	}
+}
+
+func TestPackEventsOutliveWriteTimeout(t *testing.T) {
+	h := NewHandler([]int{250, 500})
+	server := httptest.NewUnstartedServer(h.NewRouter())
+	server.Config.WriteTimeout = 100 * time.Millisecond
+	server.Start()
+	t.Cleanup(server.Close) // Runs after the streams are closed
+
+	stream := openEventStream(t, server, "")
+	readEvent(t, stream)
+
+	// Changes are still sent after the server's write timeout
+	time.Sleep(250 * time.Millisecond)
+	if _, err := h.SetPackSizes(context.Background(), []int{23, 31, 53}); err != nil {
+		t.Fatalf("SetPackSizes failed: %v", err)
+	}
+	if _, config := readEvent(t, stream); config.Version != 1 {
+		t.Errorf("Expected version 1, got %d", config.Version)
+	}
+}
+
+func TestPackEventsResume(t *testing.T) {
+	h := NewHandler([]int{250, 500})
+	h.SetPackSizes(context.Background(), []int{100})
+	server := httptest.NewServer(h.NewRouter())
+	t.Cleanup(server.Close) // Runs after the streams are closed
+
+	// A client that missed a change gets the current configuration
+	_, config := readEvent(t, openEventStream(t, server, "0"))
+	if config.Version != 1 {
+		t.Errorf("Expected version 1 after resuming from 0, got %d", config.Version)
+	}
+
+	// An up to date client only gets later changes
+	stream := openEventStream(t, server, "1")
+	h.SetPackSizes(context.Background(), []int{200})
+	ev, config := readEvent(t, stream)
+	if ev.id != "2" || len(config.PackSizes) != 1 || config.PackSizes[0] != 200 {
+		t.Errorf("Expected pack sizes [200] with ID 2 first, got %v with ID %s", config.PackSizes, ev.id)
+	}
+}
+
+func TestPackEventsStorageReload(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "packs.json")
+	h := NewHandlerWithStorage([]int{250, 500}, storage.NewStorage(tmpFile))
+	server := httptest.NewServer(h.NewRouter())
+	t.Cleanup(server.Close) // Runs after the streams are closed
+
+	stream := openEventStream(t, server, "")
+	readEvent(t, stream)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go h.WatchStorage(ctx, 10*time.Millisecond)
+
+	// Wait for the watcher to record the file's initial state
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(tmpFile, []byte(`{"pack_sizes": [10, 20], "version": 5}`), 0644); err != nil {
+		t.Fatalf("Failed to write storage file: %v", err)
+	}
+
+	ev, config := readEvent(t, stream)
+	if ev.id != "5" || len(config.PackSizes) != 2 || config.PackSizes[0] != 10 {
+		t.Errorf("Expected pack sizes [10 20] with ID 5, got %v with ID %s", config.PackSizes, ev.id)
+	}
+}
+
+func TestPackEventsSkipsSupersededChanges(t *testing.T) {
+	events := newPackEvents()
+	ch := events.subscribe()
+	defer events.unsubscribe(ch)
+
+	events.publish([]int{1}, 1)
+	events.publish([]int{2}, 2)
+
+	if got := <-ch; got.Version != 2 {
+		t.Errorf("Expected only the latest change, got version %d", got.Version)
+	}
+	select {
+	case got := <-ch:
+		t.Errorf("Expected no more changes, got version %d", got.Version)
+	default:
+	}
+}
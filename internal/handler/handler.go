@@ -1,65 +1,146 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"order-pack-calculator/internal/auth"
 	"order-pack-calculator/internal/calculator"
 	"order-pack-calculator/internal/model"
 	"order-pack-calculator/internal/storage"
-	"sort"
+	"order-pack-calculator/internal/webhook"
+	"order-pack-calculator/pkg/packing"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// Default calculation limits
+const (
+	DefaultMaxOrderQuantity   = 10000000
+	DefaultMemoryBudget       = 256 << 20
+	DefaultCalculationTimeout = 10 * time.Second
+	DefaultMaxCSVRows         = 10000
+)
+
+// Limits bounds the resources a single calculation or CSV upload may use
+// Zero values mean no limit.
+type Limits struct {
+	MaxOrderQuantity int
+	MemoryBudget     int64 // Bytes for the solver table shared by all orders
+	Timeout          time.Duration
+	MaxCSVRows       int // Orders per CSV upload
+}
+
+// DefaultLimits are the limits of a new handler
+var DefaultLimits = Limits{
+	MaxOrderQuantity: DefaultMaxOrderQuantity,
+	MemoryBudget:     DefaultMemoryBudget,
+	Timeout:          DefaultCalculationTimeout,
+	MaxCSVRows:       DefaultMaxCSVRows,
+}
+
 // Handler manages HTTP endpoints and pack configuration
 type Handler struct {
 	packSizes []int
+	version   int64           // Pack set version, bumped on every change
+	solver    *packing.Solver // Built for packSizes, nil if they are invalid
+	limits    Limits
 	mu        sync.RWMutex
-	storage   *storage.Storage // Optional persistence layer
+	writeMu   sync.Mutex            // Serialises pack size changes, which save outside mu
+	storage   *storage.Storage      // Optional persistence layer
+	history   *storage.OrderHistory // Optional record of calculations
+	cache     *resultCache          // Optional cache of calculation results
+	events    *packEvents           // Pack size changes for event streams
+	webhooks  *webhook.Dispatcher   // Optional webhook subscriptions
+	keys      *auth.Keys            // Optional API keys required on every request
+	audit     *storage.AuditLog     // Record of administrative changes
+	metrics   *serverMetrics
+	draining  atomic.Bool  // Set once the server starts shutting down
+	lastCheck storageCheck // Last readiness check of the storage file
 }
 
 // NewHandler creates a new handler with initial pack sizes
 // If storage is provided, pack sizes will be persisted to disk
 func NewHandler(initialPackSizes []int) *Handler {
-	return &Handler{
-		packSizes: initialPackSizes,
-		storage:   nil, // No persistence by default
+	h := &Handler{
+		limits:  DefaultLimits,
+		storage: nil, // No persistence by default
+		events:  newPackEvents(),
+		audit:   newMemoryAuditLog(),
 	}
+	h.metrics = newServerMetrics(h)
+	h.setPackSizes(initialPackSizes)
+
+	return h
 }
 
 // NewHandlerWithStorage creates a new handler with persistence
 func NewHandlerWithStorage(initialPackSizes []int, stor *storage.Storage) *Handler {
 	h := &Handler{
-		packSizes: initialPackSizes,
-		storage:   stor,
+		limits:  DefaultLimits,
+		storage: stor,
+		events:  newPackEvents(),
+		audit:   newMemoryAuditLog(),
 	}
+	h.metrics = newServerMetrics(h)
+	h.setPackSizes(initialPackSizes)
 
 	// Try to load pack sizes from storage
 	if stor != nil {
-		if saved, err := stor.LoadPackSizes(); err == nil && len(saved) > 0 {
-			h.packSizes = saved
-			log.Printf("Loaded pack sizes from storage: %v", saved)
+		if cfg, err := stor.Load(); err == nil && len(cfg.PackSizes) > 0 {
+			h.setPackSizes(cfg.PackSizes)
+			h.version = cfg.Version
+			slog.Info("Loaded pack sizes from storage", "pack_sizes", cfg.PackSizes, "version", cfg.Version)
 		}
 	}
 
 	return h
 }
 
+// SetOrderHistory enables recording of every calculation result
+func (h *Handler) SetOrderHistory(history *storage.OrderHistory) {
+	h.history = history
+}
+
+// SetLimits changes the resource limits of calculations
+func (h *Handler) SetLimits(limits Limits) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.limits = limits
+	h.setPackSizes(h.packSizes)
+
+	// Cached results may be over the new limits
+	h.cache.reset(h.version)
+}
+
+// EnableResultCache caches calculation results of the current pack set,
+// keeping at most maxEntries results and about maxBytes of memory
+func (h *Handler) EnableResultCache(maxEntries int, maxBytes int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.cache = newResultCache(maxEntries, maxBytes, h.version)
+}
+
 // GetPackSizes returns current pack sizes
 func (h *Handler) GetPackSizes(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		sendError(w, errMethodNotAllowed)
 		return
 	}
 
 	// Thread-safe read of pack sizes
-	h.mu.RLock()
-	sizes := make([]int, len(h.packSizes))
-	copy(sizes, h.packSizes)
-	h.mu.RUnlock()
+	set := h.snapshot()
 
 	response := model.PackSizesResponse{
-		PackSizes: sizes,
+		PackSizes: set.sizes,
+		Version:   set.version,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -69,44 +150,25 @@ func (h *Handler) GetPackSizes(w http.ResponseWriter, r *http.Request) {
 // UpdatePackSizes updates pack sizes configuration
 func (h *Handler) UpdatePackSizes(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		sendError(w, errMethodNotAllowed)
 		return
 	}
 
 	var req model.PackSizesRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		sendError(w, "Invalid request body", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &req, "pack_sizes"); err != nil {
+		sendError(w, err)
 		return
 	}
 
-	if len(req.PackSizes) == 0 {
-		sendError(w, "Pack sizes cannot be empty", http.StatusBadRequest)
+	version, err := h.SetPackSizes(r.Context(), req.PackSizes)
+	if err != nil {
+		sendError(w, err)
 		return
 	}
 
-	// Validate pack sizes (must be positive)
-	for _, size := range req.PackSizes {
-		if size <= 0 {
-			sendError(w, "Pack sizes must be positive integers", http.StatusBadRequest)
-			return
-		}
-	}
-
-	// Thread-safe update of pack sizes
-	h.mu.Lock()
-	h.packSizes = req.PackSizes
-	h.mu.Unlock()
-
-	// Persist to storage if available
-	if h.storage != nil {
-		if err := h.storage.SavePackSizes(req.PackSizes); err != nil {
-			// Log error but don't fail the request
-			log.Printf("Warning: failed to save pack sizes to storage: %v", err)
-		}
-	}
-
 	response := model.PackSizesResponse{
 		PackSizes: req.PackSizes,
+		Version:   version,
 		Message:   "Pack sizes updated successfully",
 	}
 
@@ -114,68 +176,326 @@ func (h *Handler) UpdatePackSizes(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// WatchStorage reloads pack sizes whenever the storage file changes on disk,
+// whether edited externally or saved by another instance sharing the file.
+// Invalid content is logged and the current configuration is kept.
+// Blocks until ctx is cancelled; does nothing without a storage layer.
+func (h *Handler) WatchStorage(ctx context.Context, interval time.Duration) {
+	if h.storage == nil {
+		return
+	}
+
+	h.storage.Watch(ctx, interval, func(cfg storage.Config, err error) {
+		// Apply changes one at a time with saves, reading the file again in
+		// case this instance saved since it was polled
+		h.writeMu.Lock()
+		defer h.writeMu.Unlock()
+
+		if err == nil {
+			cfg, err = h.storage.Load()
+		}
+		if err == nil {
+			err = validatePackSizes(cfg.PackSizes)
+		}
+		if err != nil {
+			h.metrics.storageError(opLoadPackSizes)
+			slog.WarnContext(ctx, "Ignoring storage file change, keeping current pack sizes", "error", err)
+			return
+		}
+
+		if h.applyStoredConfig(cfg) {
+			slog.InfoContext(ctx, "Reloaded pack sizes from storage", "pack_sizes", cfg.PackSizes, "version", cfg.Version)
+		}
+	})
+}
+
+// applyStoredConfig replaces the pack sizes with a configuration read from
+// storage unless it is older than the current one. Unversioned files count
+// as newer whenever their content differs. Reports whether it was applied.
+// Callers must hold h.writeMu.
+func (h *Handler) applyStoredConfig(cfg storage.Config) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch {
+	case cfg.Version > h.version:
+		h.version = cfg.Version
+	case cfg.Version == 0 && !slices.Equal(cfg.PackSizes, h.packSizes):
+		h.version++
+	default:
+		return false
+	}
+	h.setPackSizes(cfg.PackSizes)
+	h.cache.reset(h.version)
+	h.publishPackSizes()
+
+	return true
+}
+
 // CalculatePacks calculates optimal pack combination
 func (h *Handler) CalculatePacks(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		sendError(w, errMethodNotAllowed)
 		return
 	}
 
 	var req model.CalculateRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		sendError(w, "Invalid request body", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &req, "order_quantity"); err != nil {
+		sendError(w, err)
 		return
 	}
 
-	if req.OrderQuantity < 0 {
-		sendError(w, "Order quantity must be a non-negative integer", http.StatusBadRequest)
+	response, err := h.Calculate(r.Context(), req)
+	if err != nil {
+		sendError(w, err)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// PackSizes returns a copy of the current pack sizes and their version
+func (h *Handler) PackSizes() ([]int, int64) {
+	set := h.snapshot()
+	return set.sizes, set.version
+}
+
+// SetPackSizes validates and replaces the pack sizes, persisting them if
+// storage is configured and recording the change in the audit log under
+// the caller of ctx. Returns the new pack set version.
+func (h *Handler) SetPackSizes(ctx context.Context, packSizes []int) (int64, error) {
+	if err := validatePackSizes(packSizes); err != nil {
+		return 0, err
+	}
+
+	// Changes are made one at a time, but the file I/O happens outside mu
+	// so calculations keep using the current pack sizes meanwhile
+	h.writeMu.Lock()
+	defer h.writeMu.Unlock()
+
+	current := h.snapshot()
+	old := model.PackSizesResponse{PackSizes: current.sizes, Version: current.version}
+
+	// Persist to storage if available; the stored version becomes ours
+	var saved int64
+	if h.storage != nil {
+		cfg, err := h.storage.Save(packSizes, current.version)
+		if err != nil {
+			h.metrics.storageError(opSavePackSizes)
+			slog.WarnContext(ctx, "Failed to save pack sizes to storage", "error", err)
+			return 0, errPackSizesSave
+		}
+		saved = cfg.Version
+	}
+
+	h.mu.Lock()
+	h.setPackSizes(packSizes)
+	if saved > 0 {
+		h.version = saved
+	} else {
+		h.version++
+	}
+	version := h.version
+	h.cache.reset(version)
+	h.publishPackSizes()
+	h.mu.Unlock()
+
+	h.recordAudit(ctx, AuditPackSizesUpdated, "", old, model.PackSizesResponse{PackSizes: packSizes, Version: version})
+
+	return version, nil
+}
+
+// Calculate calculates an order with the current pack sizes and records it
+// if order history is enabled. Errors can be converted with Problem.
+func (h *Handler) Calculate(ctx context.Context, req model.CalculateRequest) (model.CalculateResponse, error) {
+	if req.OrderQuantity < 0 {
+		return model.CalculateResponse{}, errNegativeQuantity
+	}
+
+	set := h.snapshot()
+	response, err := h.calculateOrder(ctx, req, set)
+	if err != nil {
+		return model.CalculateResponse{}, calculationError(err, set.limits)
+	}
+
+	return response, nil
+}
+
+// calculateOrder calculates an order against a pack sizes snapshot and
+// records the result if order history is enabled
+func (h *Handler) calculateOrder(ctx context.Context, req model.CalculateRequest, set packSet) (model.CalculateResponse, error) {
+	response, ok := h.cache.get(set.version, req.OrderQuantity)
+	if !ok {
+		if set.solver == nil {
+			return model.CalculateResponse{}, errNoSolver
+		}
+
+		if set.limits.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, set.limits.Timeout)
+			defer cancel()
+		}
+
+		start := time.Now()
+		result, err := set.solver.SolveContext(ctx, req.OrderQuantity)
+		h.metrics.observeCalculation(req.OrderQuantity, time.Since(start))
+		if err != nil {
+			return model.CalculateResponse{}, err
+		}
+
+		response = calculator.Response(result)
+		h.cache.put(set.version, response)
+	}
+
+	response.ClientOrderID = req.ClientOrderID
+	response.SKU = req.SKU
+
+	if h.history != nil {
+		rec, err := h.history.Record(model.OrderRecord{
+			ClientOrderID:  req.ClientOrderID,
+			SKU:            req.SKU,
+			OrderQuantity:  response.OrderQuantity,
+			PackSetVersion: set.version,
+			PackSizes:      set.sizes,
+			Packs:          response.Packs,
+			TotalItems:     response.TotalItems,
+			TotalPacks:     response.TotalPacks,
+		})
+		if err != nil {
+			// Log error but don't fail the request
+			h.metrics.storageError(opRecordOrder)
+			slog.WarnContext(ctx, "Failed to record order", "error", err)
+		} else {
+			response.OrderID = rec.ID
+		}
+	}
+	h.webhooks.Publish(webhook.EventOrderCalculated, response)
+
+	return response, nil
+}
+
+// publishPackSizes notifies event streams and webhooks of the current pack
+// sizes. Callers must hold h.mu.
+func (h *Handler) publishPackSizes() {
+	h.events.publish(h.packSizes, h.version)
+	h.webhooks.Publish(webhook.EventPackSizesUpdated, model.PackSizesResponse{
+		PackSizes: slices.Clone(h.packSizes),
+		Version:   h.version,
+	})
+}
+
+// packSet is a consistent view of the pack sizes, their version and the
+// solver built for them
+type packSet struct {
+	sizes   []int
+	version int64
+	solver  *packing.Solver
+	limits  Limits
+}
+
+// snapshot returns a copy of the current pack sizes with their version and
+// solver
+func (h *Handler) snapshot() packSet {
 	h.mu.RLock()
+	defer h.mu.RUnlock()
+
 	sizes := make([]int, len(h.packSizes))
 	copy(sizes, h.packSizes)
-	h.mu.RUnlock()
 
-	// Calculate optimal packs
-	packsMap := calculator.CalculatePacks(req.OrderQuantity, sizes)
+	return packSet{sizes: sizes, version: h.version, solver: h.solver, limits: h.limits}
+}
+
+// setPackSizes replaces the pack sizes and rebuilds the solver, which is
+// shared by all calculations until the next change. Callers other than
+// constructors must hold h.mu.
+func (h *Handler) setPackSizes(packSizes []int) {
+	solver, err := packing.NewSolver(packSizes,
+		packing.WithMaxOrderQuantity(h.limits.MaxOrderQuantity),
+		packing.WithMemoryBudget(h.limits.MemoryBudget),
+	)
+	if err != nil {
+		slog.Warn("Invalid pack sizes", "pack_sizes", packSizes, "error", err)
+	}
 
-	// Convert to response format
-	packs := []model.PackBreakdown{}
-	totalItems := 0
-	totalPacks := 0
+	h.packSizes = packSizes
+	h.solver = solver
+}
 
-	// Sort by pack size descending for consistent output
-	packSizes := make([]int, 0, len(packsMap))
-	for size := range packsMap {
-		packSizes = append(packSizes, size)
+// calculationError converts a failed calculation to a problem error
+func calculationError(err error, limits Limits) *problemError {
+	switch {
+	case errors.Is(err, packing.ErrQuantityTooLarge) && limits.MaxOrderQuantity > 0:
+		return newFieldProblem(http.StatusRequestEntityTooLarge, CodeQuantityTooLarge, "order_quantity",
+			fmt.Sprintf("Order quantity must not exceed %d", limits.MaxOrderQuantity))
+	case errors.Is(err, packing.ErrQuantityTooLarge):
+		return newFieldProblem(http.StatusRequestEntityTooLarge, CodeQuantityTooLarge, "order_quantity",
+			"Order quantity is too large")
+	case errors.Is(err, packing.ErrMemoryBudget):
+		return newFieldProblem(http.StatusUnprocessableEntity, CodeMemoryBudgetExceeded, "order_quantity",
+			"Order quantity is too large for the current pack sizes")
+	case errors.Is(err, context.DeadlineExceeded):
+		return newProblem(http.StatusServiceUnavailable, CodeCalculationTimeout, "Calculation timed out")
+	case errors.Is(err, context.Canceled):
+		return newProblem(http.StatusServiceUnavailable, CodeCalculationCancelled, "Calculation cancelled")
 	}
-	sort.Sort(sort.Reverse(sort.IntSlice(packSizes)))
 
-	for _, size := range packSizes {
-		qty := packsMap[size]
-		packs = append(packs, model.PackBreakdown{
-			Size:     size,
-			Quantity: qty,
-		})
-		totalItems += size * qty
-		totalPacks += qty
+	if problem, ok := err.(*problemError); ok {
+		return problem
 	}
+	slog.Warn("Failed to calculate packs", "error", err)
+	return newProblem(http.StatusInternalServerError, CodeInternalError, "Failed to calculate packs")
+}
 
-	response := model.CalculateResponse{
-		OrderQuantity: req.OrderQuantity,
-		Packs:         packs,
-		TotalItems:    totalItems,
-		TotalPacks:    totalPacks,
+// validatePackSizes checks that pack sizes are non-empty and positive
+// Every invalid size is listed in the returned problem error.
+func validatePackSizes(packSizes []int) error {
+	if len(packSizes) == 0 {
+		return newFieldProblem(http.StatusBadRequest, CodeEmptyPackSizes, "pack_sizes",
+			"Pack sizes cannot be empty")
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	var fieldErrors []model.FieldError
+	for i, size := range packSizes {
+		switch {
+		case size <= 0:
+			fieldErrors = append(fieldErrors, model.FieldError{
+				Field:   fmt.Sprintf("pack_sizes[%d]", i),
+				Code:    CodeInvalidPackSize,
+				Message: "Pack size must be a positive integer",
+			})
+		case size > packing.MaxPackSize:
+			fieldErrors = append(fieldErrors, model.FieldError{
+				Field:   fmt.Sprintf("pack_sizes[%d]", i),
+				Code:    CodeInvalidPackSize,
+				Message: fmt.Sprintf("Pack size must be at most %d", packing.MaxPackSize),
+			})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		problem := newFieldProblem(http.StatusBadRequest, CodeInvalidPackSizes, "pack_sizes",
+			"Pack sizes must be positive integers")
+		problem.errors = fieldErrors
+		return problem
+	}
+
+	return nil
 }
 
-// sendError sends an error response
-func sendError(w http.ResponseWriter, message string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(model.ErrorResponse{Error: message})
+// serverTimeouts returns the read and write timeouts of the server handling
+// a request, or zero if there is none
+func serverTimeouts(r *http.Request) (read, write time.Duration) {
+	if srv, ok := r.Context().Value(http.ServerContextKey).(*http.Server); ok {
+		return srv.ReadTimeout, srv.WriteTimeout
+	}
+	return 0, 0
+}
+
+// extendDeadline moves a connection deadline timeout from now, so that
+// long requests may last as long as they keep making progress. A zero
+// timeout, which the server uses for no timeout, leaves it unchanged.
+func extendDeadline(set func(time.Time) error, timeout time.Duration) {
+	if timeout > 0 {
+		set(time.Now().Add(timeout))
+	}
 }
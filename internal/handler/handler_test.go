@@ -2,11 +2,19 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"order-pack-calculator/internal/model"
+	"order-pack-calculator/internal/storage"
+	"order-pack-calculator/pkg/packing"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestGetPackSizes(t *testing.T) {
@@ -75,6 +83,10 @@ func TestUpdatePackSizesInvalid(t *testing.T) {
 			name:    "zero pack size",
 			request: model.PackSizesRequest{PackSizes: []int{0, 100}},
 		},
+		{
+			name:    "pack size above the maximum",
+			request: model.PackSizesRequest{PackSizes: []int{250, packing.MaxPackSize + 1}},
+		},
 	}
 
 	for _, tt := range tests {
@@ -145,6 +157,52 @@ func TestCalculatePacksInvalid(t *testing.T) {
 	}
 }
 
+func TestCalculatePacksLimits(t *testing.T) {
+	handler := NewHandler([]int{250, 500, 1000})
+	handler.SetLimits(Limits{MaxOrderQuantity: 1000000, MemoryBudget: 1 << 20})
+
+	tests := []struct {
+		name     string
+		quantity int
+		want     int
+	}{
+		{name: "within limits", quantity: 50000, want: http.StatusOK},
+		{name: "above maximum order quantity", quantity: 2000000000, want: http.StatusRequestEntityTooLarge},
+		{name: "above memory budget", quantity: 500000, want: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(model.CalculateRequest{OrderQuantity: tt.quantity})
+			req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+
+			handler.CalculatePacks(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestCalculatePacksCancelled(t *testing.T) {
+	handler := NewHandler([]int{23, 31, 53})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	body, _ := json.Marshal(model.CalculateRequest{OrderQuantity: 5000000})
+	req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewReader(body)).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.CalculatePacks(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+}
+
 func TestPackSizesPersistence(t *testing.T) {
 	handler := NewHandler([]int{250, 500, 1000})
 
@@ -176,3 +234,264 @@ func TestPackSizesPersistence(t *testing.T) {
 		t.Errorf("Expected calculation with new pack sizes to give 263 items, got %d", calcResponse.TotalItems)
 	}
 }
+
+func TestUpdatePackSizesSaveFailure(t *testing.T) {
+	// The directory of the storage file does not exist, so saving fails
+	stor := storage.NewStorage(filepath.Join(t.TempDir(), "missing", "packs.json"))
+	handler := NewHandlerWithStorage([]int{250, 500}, stor)
+	events := handler.events.subscribe()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/packs", bytes.NewReader([]byte(`{"pack_sizes": [23, 31]}`)))
+	w := httptest.NewRecorder()
+	handler.UpdatePackSizes(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+
+	// Nothing changed that was not saved
+	sizes, version := handler.PackSizes()
+	if len(sizes) != 2 || version != 0 {
+		t.Errorf("Expected pack sizes [250 500] at version 0, got %v at version %d", sizes, version)
+	}
+	select {
+	case event := <-events:
+		t.Errorf("Expected no change to be published, got %+v", event)
+	default:
+	}
+	if _, total, _ := handler.audit.List(storage.AuditFilter{}); total != 0 {
+		t.Errorf("Expected no audit record, got %d", total)
+	}
+}
+
+func TestSolverRebuiltOnUpdate(t *testing.T) {
+	handler := NewHandler([]int{250, 500, 1000})
+
+	// Calculations share one solver until the pack sizes change
+	solver := handler.snapshot().solver
+	if solver == nil || handler.snapshot().solver != solver {
+		t.Fatal("Expected calculations to share one solver")
+	}
+
+	body, _ := json.Marshal(model.PackSizesRequest{PackSizes: []int{23, 31, 53}})
+	req := httptest.NewRequest(http.MethodPut, "/api/packs", bytes.NewReader(body))
+	handler.UpdatePackSizes(httptest.NewRecorder(), req)
+
+	set := handler.snapshot()
+	if set.solver == solver {
+		t.Fatal("Expected a new solver after updating pack sizes")
+	}
+	if sizes := set.solver.PackSizes(); len(sizes) != 3 || sizes[0] != 53 {
+		t.Errorf("Expected solver for [23 31 53], got %v", sizes)
+	}
+}
+
+func TestWatchStorage(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "pack_sizes.json")
+	stor := storage.NewStorage(tmpFile)
+	if err := stor.SavePackSizes([]int{250, 500}); err != nil {
+		t.Fatalf("Failed to save pack sizes: %v", err)
+	}
+
+	handler := NewHandlerWithStorage([]int{1000}, stor)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go handler.WatchStorage(ctx, 10*time.Millisecond)
+
+	currentSizes := func() []int {
+		handler.mu.RLock()
+		defer handler.mu.RUnlock()
+		return handler.packSizes
+	}
+	waitFor := func(want int) {
+		deadline := time.Now().Add(time.Second)
+		for time.Now().Before(deadline) {
+			if sizes := currentSizes(); len(sizes) == want {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("Timed out waiting for %d pack sizes, have %v", want, currentSizes())
+	}
+
+	// Valid change is applied
+	if err := os.WriteFile(tmpFile, []byte("[23, 31, 53]"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	waitFor(3)
+
+	// Invalid changes keep the previous configuration
+	for _, content := range []string{"[]", "[10, -5]", "not json"} {
+		if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+		if sizes := currentSizes(); len(sizes) != 3 || sizes[0] != 23 {
+			t.Errorf("Pack sizes changed after invalid content %q: %v", content, sizes)
+		}
+	}
+}
+
+func TestSharedStorageConvergence(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "pack_sizes.json")
+
+	// Two replicas with their own storage instances on a shared file
+	first := NewHandlerWithStorage([]int{250, 500}, storage.NewStorage(tmpFile))
+	second := NewHandlerWithStorage([]int{250, 500}, storage.NewStorage(tmpFile))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go second.WatchStorage(ctx, 10*time.Millisecond)
+
+	body, _ := json.Marshal(model.PackSizesRequest{PackSizes: []int{23, 31, 53}})
+	req := httptest.NewRequest(http.MethodPut, "/api/packs", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	first.UpdatePackSizes(w, req)
+
+	var updated model.PackSizesResponse
+	if err := json.NewDecoder(w.Body).Decode(&updated); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if updated.Version != 1 {
+		t.Errorf("Expected version 1, got %d", updated.Version)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		req := httptest.NewRequest(http.MethodGet, "/api/packs", nil)
+		w := httptest.NewRecorder()
+		second.GetPackSizes(w, req)
+
+		var response model.PackSizesResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if response.Version == updated.Version {
+			if len(response.PackSizes) != 3 || response.PackSizes[0] != 23 {
+				t.Errorf("Expected [23 31 53] at version %d, got %v", response.Version, response.PackSizes)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Second replica did not pick up the new pack sizes")
+}
+
+// waitForPackSizes waits until a handler uses the given pack sizes and
+// returns their version
+func waitForPackSizes(t *testing.T, handler *Handler, want []int) int64 {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		sizes, version := handler.PackSizes()
+		if slices.Equal(sizes, want) {
+			return version
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for pack sizes %v, have %v at version %d", want, sizes, version)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSharedStorageAfterBareArrayEdit(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "pack_sizes.json")
+	first := NewHandlerWithStorage([]int{250, 500}, storage.NewStorage(tmpFile))
+	second := NewHandlerWithStorage([]int{250, 500}, storage.NewStorage(tmpFile))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go first.WatchStorage(ctx, 5*time.Millisecond)
+	go second.WatchStorage(ctx, 5*time.Millisecond)
+
+	if _, err := first.SetPackSizes(context.Background(), []int{100, 200}); err != nil {
+		t.Fatalf("Failed to set pack sizes: %v", err)
+	}
+	waitForPackSizes(t, second, []int{100, 200})
+
+	// Config management writes a bare array, which both replicas reload
+	// ahead of the version 0 it has in the file
+	if err := os.WriteFile(tmpFile, []byte("[7, 9]"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	edited := waitForPackSizes(t, first, []int{7, 9})
+	waitForPackSizes(t, second, []int{7, 9})
+
+	// The next save moves forward from there, so the other replica adopts it
+	version, err := first.SetPackSizes(context.Background(), []int{42})
+	if err != nil {
+		t.Fatalf("Failed to set pack sizes: %v", err)
+	}
+	if version <= edited {
+		t.Errorf("Expected a version above %d, got %d", edited, version)
+	}
+	if got := waitForPackSizes(t, second, []int{42}); got != version {
+		t.Errorf("Expected second replica at version %d, got %d", version, got)
+	}
+}
+
+func TestSharedStorageConcurrentUpdates(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "pack_sizes.json")
+	replicas := []*Handler{
+		NewHandlerWithStorage([]int{250, 500}, storage.NewStorage(tmpFile)),
+		NewHandlerWithStorage([]int{250, 500}, storage.NewStorage(tmpFile)),
+		NewHandlerWithStorage([]int{250, 500}, storage.NewStorage(tmpFile)),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, replica := range replicas {
+		go replica.WatchStorage(ctx, time.Millisecond)
+	}
+
+	// Saves race with each replica reloading the other's saves
+	var wg sync.WaitGroup
+	for i, replica := range replicas {
+		wg.Add(1)
+		go func(i int, replica *Handler) {
+			defer wg.Done()
+			for n := 1; n <= 200; n++ {
+				if _, err := replica.SetPackSizes(context.Background(), []int{i*1000 + n}); err != nil {
+					t.Errorf("Failed to set pack sizes: %v", err)
+				}
+			}
+		}(i, replica)
+	}
+	wg.Wait()
+
+	// Both replicas end up with the last save, never an older one
+	cfg, err := storage.NewStorage(tmpFile).Load()
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	for i, replica := range replicas {
+		if version := waitForPackSizes(t, replica, cfg.PackSizes); version != cfg.Version {
+			t.Errorf("Expected replica %d at version %d, got %d", i, cfg.Version, version)
+		}
+	}
+}
+
+func TestWatchStorageWaitsForSave(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "pack_sizes.json")
+	handler := NewHandlerWithStorage([]int{250, 500}, storage.NewStorage(tmpFile))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go handler.WatchStorage(ctx, time.Millisecond)
+
+	// While a save is in progress, a newer save by another replica is not
+	// applied, so the save cannot overwrite it afterwards
+	handler.writeMu.Lock()
+	if err := storage.NewStorage(tmpFile).SavePackSizes([]int{23, 31}); err != nil {
+		t.Fatalf("Failed to save pack sizes: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if sizes, _ := handler.PackSizes(); !slices.Equal(sizes, []int{250, 500}) {
+		t.Errorf("Expected no reload during a save, got %v", sizes)
+	}
+	handler.writeMu.Unlock()
+
+	waitForPackSizes(t, handler, []int{23, 31})
+}
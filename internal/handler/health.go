@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"order-pack-calculator/internal/model"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Probe paths, served outside the API without an API key
+const (
+	HealthPath    = "/healthz"
+	ReadinessPath = "/readyz"
+	VersionPath   = "/version"
+)
+
+// Health statuses
+const (
+	statusOK          = "ok"
+	statusFailed      = "failed"
+	statusUnavailable = "unavailable"
+)
+
+// Version overrides the version reported by VersionPath, e.g. with
+// -ldflags "-X order-pack-calculator/internal/handler.Version=v1.2.3".
+// By default the module version stamped by the Go toolchain is used.
+var Version string
+
+// readBuildInfo reads the build information embedded in the binary
+var readBuildInfo = debug.ReadBuildInfo
+
+// storageCheckTTL is how long the result of a storage check is reused, so
+// frequent probes do not write to the data directory every time
+var storageCheckTTL = 5 * time.Second
+
+// storageCheck remembers the result of the last storage check
+type storageCheck struct {
+	mu      sync.Mutex
+	checked time.Time
+	err     error
+}
+
+// Healthz reports that the process is alive and serving requests
+func (h *Handler) Healthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		sendError(w, errMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(model.HealthResponse{Status: statusOK})
+}
+
+// Readyz reports whether the server can serve traffic: the pack sizes
+// must be usable, the server not shutting down and, if persistence is
+// enabled, the storage file readable, valid and writable. Responds 503
+// listing the failed checks otherwise. Probes are not authenticated, so
+// the reasons are only logged.
+func (h *Handler) Readyz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		sendError(w, errMethodNotAllowed)
+		return
+	}
+
+	response := model.HealthResponse{Status: statusOK, Checks: h.readinessChecks(r.Context())}
+	status := http.StatusOK
+	for _, check := range response.Checks {
+		if check.Status != statusOK {
+			response.Status = statusUnavailable
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// readinessChecks runs the readiness checks
+func (h *Handler) readinessChecks(ctx context.Context) []model.HealthCheck {
+	config := model.HealthCheck{Name: "config", Status: statusOK}
+	if set := h.snapshot(); set.solver == nil {
+		config.Status = statusFailed
+		config.Error = "no valid pack sizes configured"
+	}
+	checks := []model.HealthCheck{config}
+
+	if h.draining.Load() {
+		checks = append(checks, model.HealthCheck{Name: "shutdown", Status: statusFailed, Error: "server is shutting down"})
+	}
+
+	if h.storage != nil {
+		check := model.HealthCheck{Name: "storage", Status: statusOK}
+		if err := h.checkStorage(ctx); err != nil {
+			check.Status = statusFailed
+			check.Error = "storage file is not readable, valid and writable"
+		}
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// checkStorage checks the storage file unless it was checked within
+// storageCheckTTL, in which case the previous result is returned
+func (h *Handler) checkStorage(ctx context.Context) error {
+	h.lastCheck.mu.Lock()
+	defer h.lastCheck.mu.Unlock()
+
+	if time.Since(h.lastCheck.checked) < storageCheckTTL {
+		return h.lastCheck.err
+	}
+
+	err := h.storage.Check()
+	if err != nil {
+		h.metrics.storageError(opCheckStorage)
+		slog.WarnContext(ctx, "Storage readiness check failed", "error", err)
+	}
+	h.lastCheck.checked = time.Now()
+	h.lastCheck.err = err
+	return err
+}
+
+// GetVersion returns the version, commit and Go version of the build
+func (h *Handler) GetVersion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendError(w, errMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(buildVersion())
+}
+
+// buildVersion collects the build information of the binary
+func buildVersion() model.VersionResponse {
+	response := model.VersionResponse{
+		Version:   Version,
+		GoVersion: runtime.Version(),
+	}
+
+	if info, ok := readBuildInfo(); ok {
+		if response.Version == "" {
+			response.Version = info.Main.Version
+		}
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				response.Commit = setting.Value
+			case "vcs.time":
+				response.CommitTime = setting.Value
+			case "vcs.modified":
+				response.Modified = setting.Value == "true"
+			}
+		}
+	}
+
+	if response.Version == "" {
+		response.Version = "(devel)"
+	}
+	return response
+}
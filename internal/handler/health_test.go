@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"order-pack-calculator/internal/model"
+	"order-pack-calculator/internal/storage"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+// probe sends a GET request to a probe path and decodes the response
+func probe(t *testing.T, handler *Handler, path string, wantStatus int, v any) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	handler.NewRouter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if w.Code != wantStatus {
+		t.Fatalf("Expected status %d, got %d: %s", wantStatus, w.Code, w.Body.String())
+	}
+	if err := json.NewDecoder(w.Body).Decode(v); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	var response model.HealthResponse
+	probe(t, NewHandler([]int{250, 500}), HealthPath, http.StatusOK, &response)
+
+	if response.Status != "ok" {
+		t.Errorf("Expected status ok, got %s", response.Status)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	dir := t.TempDir()
+	invalidFile := filepath.Join(dir, "invalid.json")
+	os.WriteFile(invalidFile, []byte("not json"), 0644)
+
+	tests := []struct {
+		name       string
+		handler    *Handler
+		wantStatus int
+		wantChecks map[string]string
+	}{
+		{
+			"in memory",
+			NewHandler([]int{250, 500}),
+			http.StatusOK, map[string]string{"config": "ok"},
+		},
+		{
+			"storage",
+			NewHandlerWithStorage([]int{250, 500}, storage.NewStorage(filepath.Join(dir, "packs.json"))),
+			http.StatusOK, map[string]string{"config": "ok", "storage": "ok"},
+		},
+		{
+			"unwritable storage",
+			NewHandlerWithStorage([]int{250, 500}, storage.NewStorage(filepath.Join(dir, "missing", "packs.json"))),
+			http.StatusServiceUnavailable, map[string]string{"config": "ok", "storage": "failed"},
+		},
+		{
+			"invalid storage file",
+			NewHandlerWithStorage([]int{250, 500}, storage.NewStorage(invalidFile)),
+			http.StatusServiceUnavailable, map[string]string{"config": "ok", "storage": "failed"},
+		},
+		{
+			"no valid pack sizes",
+			NewHandler([]int{}),
+			http.StatusServiceUnavailable, map[string]string{"config": "failed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response model.HealthResponse
+			probe(t, tt.handler, ReadinessPath, tt.wantStatus, &response)
+
+			wantStatus := "ok"
+			if tt.wantStatus != http.StatusOK {
+				wantStatus = "unavailable"
+			}
+			if response.Status != wantStatus {
+				t.Errorf("Expected status %s, got %s", wantStatus, response.Status)
+			}
+
+			checks := make(map[string]string)
+			for _, check := range response.Checks {
+				checks[check.Name] = check.Status
+				if check.Status == "failed" && check.Error == "" {
+					t.Errorf("Expected an error for the failed %s check", check.Name)
+				}
+			}
+			if len(checks) != len(tt.wantChecks) {
+				t.Errorf("Expected checks %v, got %v", tt.wantChecks, checks)
+			}
+			for name, status := range tt.wantChecks {
+				if checks[name] != status {
+					t.Errorf("Expected check %s to be %s, got %q", name, status, checks[name])
+				}
+			}
+		})
+	}
+}
+
+func TestReadyzHidesStorageError(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "invalid.json")
+	os.WriteFile(file, []byte("not json"), 0644)
+
+	var response model.HealthResponse
+	probe(t, NewHandlerWithStorage([]int{250, 500}, storage.NewStorage(file)), ReadinessPath, http.StatusServiceUnavailable, &response)
+
+	for _, check := range response.Checks {
+		if strings.Contains(check.Error, file) || strings.Contains(check.Error, "invalid character") {
+			t.Errorf("Expected a generic error for the %s check, got %q", check.Name, check.Error)
+		}
+	}
+}
+
+func TestReadyzCachesStorageCheck(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "packs.json")
+	handler := NewHandlerWithStorage([]int{250, 500}, storage.NewStorage(file))
+
+	var response model.HealthResponse
+	probe(t, handler, ReadinessPath, http.StatusOK, &response)
+
+	// The file breaks, but the last result is reused within the TTL
+	os.WriteFile(file, []byte("not json"), 0644)
+	probe(t, handler, ReadinessPath, http.StatusOK, &response)
+
+	original := storageCheckTTL
+	t.Cleanup(func() { storageCheckTTL = original })
+	storageCheckTTL = 0
+	probe(t, handler, ReadinessPath, http.StatusServiceUnavailable, &response)
+}
+
+func TestVersion(t *testing.T) {
+	original := readBuildInfo
+	t.Cleanup(func() { readBuildInfo = original })
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{
+			Main: debug.Module{Path: "order-pack-calculator", Version: "v1.4.0"},
+			Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "7ac66c4"},
+				{Key: "vcs.time", Value: "2024-05-01T10:00:00Z"},
+				{Key: "vcs.modified", Value: "true"},
+			},
+		}, true
+	}
+
+	var response model.VersionResponse
+	probe(t, NewHandler([]int{250, 500}), VersionPath, http.StatusOK, &response)
+
+	want := model.VersionResponse{
+		Version:    "v1.4.0",
+		Commit:     "7ac66c4",
+		CommitTime: "2024-05-01T10:00:00Z",
+		Modified:   true,
+		GoVersion:  runtime.Version(),
+	}
+	if response != want {
+		t.Errorf("Expected %+v, got %+v", want, response)
+	}
+
+	// A version set at link time wins
+	Version = "v2.0.0-rc1"
+	t.Cleanup(func() { Version = "" })
+	probe(t, NewHandler([]int{250, 500}), VersionPath, http.StatusOK, &response)
+	if response.Version != "v2.0.0-rc1" {
+		t.Errorf("Expected version v2.0.0-rc1, got %s", response.Version)
+	}
+}
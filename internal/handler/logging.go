@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"order-pack-calculator/internal/logging"
+	"time"
+)
+
+// RequestIDHeader carries the ID of a request
+// A client or proxy may set it; otherwise, or if it is not a valid ID, one
+// is generated. Either way it is returned in the response and attached to
+// every log record and audit record about the request.
+const RequestIDHeader = "X-Request-ID"
+
+// requestLogger assigns every request an ID and writes an access log
+// record once it has been served. Metrics scrapes and health probes are
+// only logged at debug level.
+func requestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestID := r.Header.Get(RequestIDHeader)
+		if !logging.ValidRequestID(requestID) {
+			requestID = logging.NewRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+		ctx := logging.WithRequestID(r.Context(), requestID)
+
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case r.URL.Path == MetricsPath || r.URL.Path == HealthPath || r.URL.Path == ReadinessPath:
+			level = slog.LevelDebug
+		}
+
+		slog.Log(ctx, level, "Request served",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", status,
+			"duration_ms", float64(time.Since(start).Microseconds())/1000,
+			"bytes", rec.bytes,
+			"remote_addr", r.RemoteAddr,
+			"user_agent", r.UserAgent(),
+		)
+	})
+}
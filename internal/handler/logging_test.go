@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"order-pack-calculator/internal/logging"
+	"order-pack-calculator/internal/storage"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// captureLogs sends log records to the returned buffer as JSON for the rest
+// of the test
+func captureLogs(t *testing.T, level string) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, level, logging.FormatJSON)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	return &buf
+}
+
+// logRecords parses the captured records with the given message
+func logRecords(t *testing.T, buf *bytes.Buffer, msg string) []map[string]any {
+	t.Helper()
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Expected JSON log records, got %s", line)
+		}
+		if record["msg"] == msg {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+func TestAccessLog(t *testing.T) {
+	logs := captureLogs(t, "info")
+	r := NewHandler([]int{250, 500}).NewRouter()
+
+	req := httptest.NewRequest(http.MethodPost, APIPrefix+"/calculate", strings.NewReader(`{"order_quantity": 251}`))
+	req.Header.Set(RequestIDHeader, "req-42")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get(RequestIDHeader); got != "req-42" {
+		t.Errorf("Expected request ID req-42 in the response, got %q", got)
+	}
+
+	records := logRecords(t, logs, "Request served")
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 access log record, got %d: %s", len(records), logs.String())
+	}
+	record := records[0]
+	if record["method"] != "POST" || record["path"] != APIPrefix+"/calculate" || record["status"] != float64(200) || record["request_id"] != "req-42" {
+		t.Errorf("Unexpected access log record: %v", record)
+	}
+	if _, ok := record["duration_ms"].(float64); !ok {
+		t.Errorf("Expected a duration, got %v", record)
+	}
+	if record["bytes"] != float64(w.Body.Len()) {
+		t.Errorf("Expected %d bytes, got %v", w.Body.Len(), record["bytes"])
+	}
+}
+
+func TestAccessLogGeneratesRequestIDs(t *testing.T) {
+	logs := captureLogs(t, "info")
+	r := NewHandler([]int{250, 500}).NewRouter()
+
+	for _, requestID := range []string{"", strings.Repeat("x", 129), "id with spaces", "id\"injected", "<script>"} {
+		req := httptest.NewRequest(http.MethodGet, APIPrefix+"/packs", nil)
+		if requestID != "" {
+			req.Header.Set(RequestIDHeader, requestID)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		got := w.Header().Get(RequestIDHeader)
+		if got == "" || got == requestID {
+			t.Errorf("Expected a generated request ID, got %q", got)
+		}
+	}
+
+	// Requests outside the API are logged too; scrapes only at debug level
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+
+	records := logRecords(t, logs, "Request served")
+	if len(records) != 6 || records[5]["status"] != float64(404) {
+		t.Errorf("Expected 6 access log records ending with a 404, got %v", records)
+	}
+}
+
+func TestRequestIDInHandlerLogs(t *testing.T) {
+	logs := captureLogs(t, "info")
+
+	// The directory of the audit log does not exist, so recording fails
+	audit, err := storage.NewAuditLog(filepath.Join(t.TempDir(), "missing", "audit.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	handler := NewHandler([]int{250, 500})
+	handler.SetAuditLog(audit)
+
+	req := httptest.NewRequest(http.MethodPut, APIPrefix+"/packs", strings.NewReader(`{"pack_sizes": [100]}`))
+	req.Header.Set(RequestIDHeader, "req-7")
+	handler.NewRouter().ServeHTTP(httptest.NewRecorder(), req)
+
+	records := logRecords(t, logs, "Failed to record change in the audit log")
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 audit failure record, got %d: %s", len(records), logs.String())
+	}
+	if records[0]["request_id"] != "req-7" || records[0]["level"] != "WARN" || records[0]["action"] != AuditPackSizesUpdated {
+		t.Errorf("Expected a warning with request ID req-7, got %v", records[0])
+	}
+}
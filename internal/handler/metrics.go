@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"order-pack-calculator/internal/metrics"
+	"order-pack-calculator/internal/model"
+	"strconv"
+	"time"
+)
+
+// MetricsPath serves the server metrics in the Prometheus text format
+const MetricsPath = "/metrics"
+
+// Storage operations counted when they fail
+const (
+	opSavePackSizes = "save_pack_sizes"
+	opLoadPackSizes = "load_pack_sizes"
+	opCheckStorage  = "check_storage"
+	opRecordOrder   = "record_order"
+	opReadOrders    = "read_orders"
+	opRecordAudit   = "record_audit"
+	opReadAudit     = "read_audit"
+	opSaveWebhooks  = "save_webhooks"
+)
+
+// orderSizeBuckets are the upper bounds (exclusive) of the order size
+// ranges calculation durations are reported by
+var orderSizeBuckets = []int{1000, 10000, 100000, 1000000}
+
+// serverMetrics are the metrics of a handler
+type serverMetrics struct {
+	registry            *metrics.Registry
+	requests            *metrics.CounterVec
+	requestDuration     *metrics.HistogramVec
+	calculationDuration *metrics.HistogramVec
+	storageErrors       *metrics.CounterVec
+}
+
+// newServerMetrics registers the metrics of h
+func newServerMetrics(h *Handler) *serverMetrics {
+	r := metrics.NewRegistry()
+	m := &serverMetrics{
+		registry: r,
+		requests: r.NewCounterVec("packcalc_http_requests_total",
+			"HTTP API requests by route, method and status.", "route", "method", "status"),
+		requestDuration: r.NewHistogramVec("packcalc_http_request_duration_seconds",
+			"HTTP API request latency by route, method and status.", metrics.DefaultBuckets, "route", "method", "status"),
+		calculationDuration: r.NewHistogramVec("packcalc_calculation_duration_seconds",
+			"Time spent solving orders that were not cached, by order size.", metrics.DefaultBuckets, "order_size"),
+		storageErrors: r.NewCounterVec("packcalc_storage_errors_total",
+			"Failed reads and writes of the storage files by operation.", "operation"),
+	}
+
+	r.NewGaugeFunc("packcalc_pack_set_version", "Version of the current pack sizes.", func() float64 {
+		h.mu.RLock()
+		defer h.mu.RUnlock()
+		return float64(h.version)
+	})
+	r.NewGaugeFunc("packcalc_pack_sizes", "Number of configured pack sizes.", func() float64 {
+		h.mu.RLock()
+		defer h.mu.RUnlock()
+		return float64(len(h.packSizes))
+	})
+	r.NewGaugeFunc("packcalc_solver_table_entries", "Totals covered by the dynamic programming table of the current pack sizes.", func() float64 {
+		h.mu.RLock()
+		defer h.mu.RUnlock()
+		if h.solver == nil {
+			return 0
+		}
+		return float64(h.solver.TableSize())
+	})
+	r.NewCounterFunc("packcalc_storage_skipped_lines_total", "Lines of the order history and audit log skipped because they could not be parsed.", func() float64 {
+		skipped := h.audit.SkippedLines()
+		if h.history != nil {
+			skipped += h.history.SkippedLines()
+		}
+		return float64(skipped)
+	})
+	r.NewCounterFunc("packcalc_webhook_deliveries_dropped_total", "Webhook deliveries that became dead letters because the delivery queue was full.", func() float64 {
+		return float64(h.webhooks.Dropped())
+	})
+	r.NewCounterFunc("packcalc_cache_hits_total", "Calculations answered from the result cache.", func() float64 {
+		return float64(h.cacheStats().Hits)
+	})
+	r.NewCounterFunc("packcalc_cache_misses_total", "Calculations not found in the result cache.", func() float64 {
+		return float64(h.cacheStats().Misses)
+	})
+	r.NewCounterFunc("packcalc_cache_evictions_total", "Results evicted from the result cache.", func() float64 {
+		return float64(h.cacheStats().Evictions)
+	})
+	r.NewGaugeFunc("packcalc_cache_entries", "Results held by the result cache.", func() float64 {
+		return float64(h.cacheStats().Entries)
+	})
+	r.NewGaugeFunc("packcalc_cache_bytes", "Approximate memory held by the result cache.", func() float64 {
+		return float64(h.cacheStats().Bytes)
+	})
+
+	return m
+}
+
+// cacheStats returns the counters of the result cache
+func (h *Handler) cacheStats() model.CacheStats {
+	h.mu.RLock()
+	cache := h.cache
+	h.mu.RUnlock()
+
+	return cache.stats()
+}
+
+// observeRequest records a served API request
+// A status of 0 means the handler wrote nothing, which is sent as 200.
+func (m *serverMetrics) observeRequest(route, method string, status int, elapsed time.Duration) {
+	if status == 0 {
+		status = http.StatusOK
+	}
+	code := strconv.Itoa(status)
+	m.requests.Inc(route, method, code)
+	m.requestDuration.Observe(elapsed.Seconds(), route, method, code)
+}
+
+// observeCalculation records the time spent solving an order
+func (m *serverMetrics) observeCalculation(orderQty int, elapsed time.Duration) {
+	m.calculationDuration.Observe(elapsed.Seconds(), orderSizeLabel(orderQty))
+}
+
+// storageError counts a failed storage operation
+func (m *serverMetrics) storageError(operation string) {
+	m.storageErrors.Inc(operation)
+}
+
+// orderSizeLabel returns the order size range of a quantity, e.g. 1000-9999
+func orderSizeLabel(qty int) string {
+	lower := 0
+	for _, upper := range orderSizeBuckets {
+		if qty < upper {
+			return strconv.Itoa(lower) + "-" + strconv.Itoa(upper-1)
+		}
+		lower = upper
+	}
+	return strconv.Itoa(lower) + "+"
+}
+
+// ServeMetrics serves the metrics in the Prometheus text format
+func (h *Handler) ServeMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendError(w, errMethodNotAllowed)
+		return
+	}
+
+	h.metrics.registry.ServeHTTP(w, r)
+}
+
+// statusRecorder remembers the status and size of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// WriteHeader records the first status written
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// Write records an implicit 200 status and counts the bytes written
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	n, err := s.ResponseWriter.Write(b)
+	s.bytes += int64(n)
+	return n, err
+}
+
+// Flush sends buffered data to the client, for handlers that stream with
+// http.Flusher
+func (s *statusRecorder) Flush() {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	http.NewResponseController(s.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g.
+// to flush event streams
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// GetMetrics returns server metrics such as result cache hits and misses
+func (h *Handler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendError(w, errMethodNotAllowed)
+		return
+	}
+
+	response := model.MetricsResponse{
+		Cache: h.cache.stats(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"order-pack-calculator/internal/metrics"
+	"order-pack-calculator/internal/storage"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// scrapeMetrics returns the Prometheus metrics served by a router
+func scrapeMetrics(t *testing.T, r http.Handler) string {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); got != metrics.ContentType {
+		t.Errorf("Expected content type %s, got %s", metrics.ContentType, got)
+	}
+	return w.Body.String()
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	handler := NewHandler([]int{250, 500, 1000})
+	r := handler.NewRouter()
+
+	requests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodPost, "/calculate", `{"order_quantity": 251}`},
+		{http.MethodPost, "/calculate", `{"order_quantity": 12001}`},
+		{http.MethodPost, "/calculate", `{"order_quantity": -1}`},
+		{http.MethodPut, "/packs", `{"pack_sizes": [23, 31, 53]}`},
+		{http.MethodGet, "/orders/unknown", ""},
+	}
+	for _, req := range requests {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(req.method, APIPrefix+req.path, strings.NewReader(req.body)))
+	}
+
+	body := scrapeMetrics(t, r)
+	for _, want := range []string{
+		`packcalc_http_requests_total{route="/api/v1/calculate",method="POST",status="200"} 2`,
+		`packcalc_http_requests_total{route="/api/v1/calculate",method="POST",status="400"} 1`,
+		`packcalc_http_requests_total{route="/api/v1/packs",method="PUT",status="200"} 1`,
+		`packcalc_http_requests_total{route="/api/v1/orders/{id}",method="GET",status="404"} 1`,
+		`packcalc_http_request_duration_seconds_count{route="/api/v1/calculate",method="POST",status="200"} 2`,
+		`packcalc_calculation_duration_seconds_count{order_size="0-999"} 1`,
+		`packcalc_calculation_duration_seconds_count{order_size="10000-99999"} 1`,
+		"packcalc_pack_set_version 1\n",
+		"packcalc_pack_sizes 3\n",
+		"packcalc_solver_table_entries 0\n", // Replaced with the pack sizes
+		"# TYPE packcalc_storage_errors_total counter\n",
+		"packcalc_storage_skipped_lines_total 0\n",
+		"packcalc_webhook_deliveries_dropped_total 0\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %q in metrics:\n%s", want, body)
+		}
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, APIPrefix+"/calculate", strings.NewReader(`{"order_quantity": 100}`)))
+	if body := scrapeMetrics(t, r); strings.Contains(body, "packcalc_solver_table_entries 0\n") {
+		t.Errorf("Expected the solver table to grow, got:\n%s", body)
+	}
+}
+
+func TestPrometheusMetricsStorageErrors(t *testing.T) {
+	// The directory of the storage file does not exist, so saving fails
+	stor := storage.NewStorage(filepath.Join(t.TempDir(), "missing", "packs.json"))
+	handler := NewHandlerWithStorage([]int{250, 500}, stor)
+	r := handler.NewRouter()
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, APIPrefix+"/packs", strings.NewReader(`{"pack_sizes": [100]}`)))
+
+	if body := scrapeMetrics(t, r); !strings.Contains(body, `packcalc_storage_errors_total{operation="save_pack_sizes"} 1`) {
+		t.Errorf("Expected a failed save to be counted, got:\n%s", body)
+	}
+}
+
+func TestOrderSizeLabel(t *testing.T) {
+	tests := map[int]string{
+		0:        "0-999",
+		999:      "0-999",
+		1000:     "1000-9999",
+		500000:   "100000-999999",
+		10000000: "1000000+",
+	}
+
+	for qty, want := range tests {
+		if got := orderSizeLabel(qty); got != want {
+			t.Errorf("orderSizeLabel(%d) = %s, want %s", qty, got, want)
+		}
+	}
+}
+
+func TestStatusRecorderFlush(t *testing.T) {
+	w := httptest.NewRecorder()
+	var rw http.ResponseWriter = &statusRecorder{ResponseWriter: w}
+
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		t.Fatal("Expected the recorder to implement http.Flusher")
+	}
+	flusher.Flush()
+	if !w.Flushed || rw.(*statusRecorder).status != http.StatusOK {
+		t.Errorf("Expected a flushed 200 response, got flushed=%v status=%d", w.Flushed, rw.(*statusRecorder).status)
+	}
+}
@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"order-pack-calculator/internal/openapi"
+	"order-pack-calculator/internal/webhook"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenAPIDocument(t *testing.T) {
+	r := NewHandler([]int{250, 500}).NewRouter()
+
+	req := httptest.NewRequest(http.MethodGet, OpenAPIPath, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var doc openapi.Document
+	if err := json.NewDecoder(w.Body).Decode(&doc); err != nil {
+		t.Fatalf("Failed to decode document: %v", err)
+	}
+	if doc.OpenAPI != openapi.Version {
+		t.Errorf("Expected OpenAPI %s, got %s", openapi.Version, doc.OpenAPI)
+	}
+
+	// Every registered route is documented, legacy aliases as deprecated
+	for _, route := range r.Routes() {
+		op := doc.Operation(route.Method, route.Path)
+		if op == nil {
+			t.Errorf("%s %s is not documented", route.Method, route.Path)
+			continue
+		}
+		if op.Deprecated != route.Deprecated {
+			t.Errorf("%s %s: expected deprecated %v, got %v", route.Method, route.Path, route.Deprecated, op.Deprecated)
+		}
+	}
+
+	getOrder := doc.Operation(http.MethodGet, APIPrefix+"/orders/{id}")
+	if getOrder == nil || len(getOrder.Parameters) != 1 || getOrder.Parameters[0].In != "path" {
+		t.Errorf("Expected the id path parameter to be documented, got %+v", getOrder)
+	}
+}
+
+// TestOpenAPIMatchesHandlers fails when a handler's request or response
+// type drifts from the document: every request is checked against the
+// documented request schema and every response against the documented
+// response schema, rejecting undocumented and missing properties. Every
+// API route must be exercised by at least one step.
+func TestOpenAPIMatchesHandlers(t *testing.T) {
+	// Deliveries fail so that there is a dead letter to redeliver
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+	dispatcher, _ := webhook.New(nil, webhook.WithRetries(1, 0, 0))
+	defer dispatcher.Close(context.Background())
+
+	h := newHistoryHandler(t)
+	h.EnableResultCache(DefaultCacheEntries, DefaultCacheBytes)
+	h.SetWebhooks(dispatcher)
+	r := h.NewRouter()
+	doc := OpenAPI(r.Routes())
+
+	// IDs returned by earlier steps, substituted into later paths
+	ids := map[string]string{}
+	steps := []struct {
+		method     string
+		path       string // Relative to APIPrefix, {order}, {webhook} and {dead} are IDs returned earlier
+		body       string
+		wantStatus int
+	}{
+		{http.MethodPost, "/webhooks", `{"url": "` + receiver.URL + `", "events": ["pack_sizes.updated"], "secret": "s3cret"}`, http.StatusCreated},
+		{http.MethodGet, "/webhooks", "", http.StatusOK},
+		{http.MethodGet, "/webhooks/{webhook}", "", http.StatusOK},
+		{http.MethodGet, "/packs", "", http.StatusOK},
+		{http.MethodGet, "/packs/events", "", http.StatusOK},
+		{http.MethodPut, "/packs", `{"pack_sizes": [250, 500, 1000]}`, http.StatusOK},
+		{http.MethodPost, "/calculate", `{"order_quantity": 1001, "client_order_id": "PO-1", "sku": "A1"}`, http.StatusOK},
+		{http.MethodPost, "/calculate/csv", "order_id,quantity\nPO-2,251\n", http.StatusOK},
+		{http.MethodGet, "/orders?limit=10", "", http.StatusOK},
+		{http.MethodGet, "/orders/{order}", "", http.StatusOK},
+		{http.MethodGet, "/analytics?bucket=day", "", http.StatusOK},
+		{http.MethodGet, "/metrics", "", http.StatusOK},
+		{http.MethodGet, "/audit?limit=10", "", http.StatusOK},
+		{http.MethodGet, "/webhooks/deliveries", "", http.StatusOK},
+		{http.MethodGet, "/webhooks/dead-letters", "", http.StatusOK},
+		{http.MethodPost, "/webhooks/dead-letters/{dead}/redeliver", "", http.StatusAccepted},
+		{http.MethodDelete, "/webhooks/{webhook}", "", http.StatusNoContent},
+		{http.MethodPut, "/packs", `{"pack_sizes": [0, -1]}`, http.StatusBadRequest},
+		{http.MethodGet, "/orders/unknown", "", http.StatusNotFound},
+		{http.MethodPost, "/webhooks", `{"url": "ftp://example.com", "events": ["unknown"], "secret": ""}`, http.StatusBadRequest},
+	}
+
+	covered := map[string]bool{}
+	for _, step := range steps {
+		if step.path == "/webhooks/dead-letters" {
+			// Wait for the failed delivery of the pack sizes update
+			deadline := time.Now().Add(time.Second)
+			for len(dispatcher.DeadLetters()) == 0 && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+		}
+
+		path := step.path
+		for name, id := range ids {
+			path = strings.Replace(path, "{"+name+"}", id, 1)
+		}
+		req := httptest.NewRequest(step.method, APIPrefix+path, strings.NewReader(step.body))
+		if step.path == "/packs/events" {
+			// End the stream after the first event
+			ctx, cancel := context.WithCancel(req.Context())
+			cancel()
+			req = req.WithContext(ctx)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != step.wantStatus {
+			t.Fatalf("%s %s: expected status %d, got %d: %s", step.method, path, step.wantStatus, w.Code, w.Body.String())
+		}
+
+		route, _, _ := strings.Cut(APIPrefix+step.path, "?")
+		route = strings.NewReplacer("{order}", "{id}", "{webhook}", "{id}", "{dead}", "{id}").Replace(route)
+		if path == "/orders/unknown" {
+			route = APIPrefix + "/orders/{id}"
+		}
+
+		covered[step.method+" "+route] = true
+
+		op := doc.Operation(step.method, route)
+		if op == nil {
+			t.Fatalf("%s %s is not documented", step.method, route)
+		}
+		if op.RequestBody != nil {
+			if media, ok := op.RequestBody.Content["application/json"]; ok {
+				if err := doc.ValidateJSON(media.Schema, []byte(step.body)); err != nil {
+					t.Errorf("%s %s request: %v", step.method, route, err)
+				}
+			}
+		}
+		if err := doc.ValidateResponse(step.method, route, w.Code, w.Header().Get("Content-Type"), w.Body.Bytes()); err != nil {
+			t.Errorf("%s %s response: %v", step.method, route, err)
+		}
+
+		var response struct {
+			ID         string `json:"id"`
+			OrderID    string `json:"order_id"`
+			Deliveries []struct {
+				ID string `json:"id"`
+			} `json:"deliveries"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		switch step.path {
+		case "/calculate":
+			ids["order"] = response.OrderID
+		case "/webhooks":
+			if w.Code == http.StatusCreated {
+				ids["webhook"] = response.ID
+			}
+		case "/webhooks/dead-letters":
+			if len(response.Deliveries) > 0 {
+				ids["dead"] = response.Deliveries[0].ID
+			}
+		}
+	}
+
+	// New routes need a step; the legacy aliases share their handlers
+	for _, route := range r.Routes() {
+		if !route.Deprecated && !covered[route.Method+" "+route.Path] {
+			t.Errorf("%s %s is not covered by any step", route.Method, route.Path)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"order-pack-calculator/internal/model"
+	"order-pack-calculator/internal/storage"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultOrdersLimit = 50
+	maxOrdersLimit     = 500
+)
+
+// ListOrders returns recorded calculations, newest first
+// Query parameters: from, to (RFC 3339), sku, version, limit, offset
+func (h *Handler) ListOrders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendError(w, errMethodNotAllowed)
+		return
+	}
+
+	if h.history == nil {
+		sendError(w, errHistoryDisabled)
+		return
+	}
+
+	filter, err := parseOrderFilter(r)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	orders, total, err := h.history.List(filter)
+	if err != nil {
+		h.metrics.storageError(opReadOrders)
+		slog.ErrorContext(r.Context(), "Failed to list orders", "error", err)
+		sendError(w, errHistoryRead)
+		return
+	}
+
+	response := model.OrderListResponse{
+		Orders: orders,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetOrder returns a single recorded calculation by ID
+func (h *Handler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendError(w, errMethodNotAllowed)
+		return
+	}
+
+	if h.history == nil {
+		sendError(w, errHistoryDisabled)
+		return
+	}
+
+	rec, found, err := h.history.Get(r.PathValue("id"))
+	if err != nil {
+		h.metrics.storageError(opReadOrders)
+		slog.ErrorContext(r.Context(), "Failed to get order", "error", err)
+		sendError(w, errHistoryRead)
+		return
+	}
+	if !found {
+		sendError(w, errOrderNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rec)
+}
+
+// parseOrderFilter reads selection and pagination query parameters
+func parseOrderFilter(r *http.Request) (storage.OrderFilter, error) {
+	filter, err := parseOrderSelection(r)
+	if err != nil {
+		return filter, err
+	}
+	filter.Limit, filter.Offset, err = parsePage(r)
+
+	return filter, err
+}
+
+// parsePage reads the limit and offset query parameters of listings
+func parsePage(r *http.Request) (limit, offset int, err error) {
+	query := r.URL.Query()
+	limit = defaultOrdersLimit
+
+	if v := query.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxOrdersLimit {
+			return 0, 0, errBadParam("limit")
+		}
+	}
+
+	if v := query.Get("offset"); v != "" {
+		offset, err = strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return 0, 0, errBadParam("offset")
+		}
+	}
+
+	return limit, offset, nil
+}
+
+// parseOrderSelection reads the time range, SKU and pack set version
+// query parameters shared by order listing and analytics
+func parseOrderSelection(r *http.Request) (storage.OrderFilter, error) {
+	query := r.URL.Query()
+	filter := storage.OrderFilter{SKU: query.Get("sku")}
+
+	var err error
+	if filter.From, err = parseTimeParam(query.Get("from")); err != nil {
+		return filter, errBadParam("from")
+	}
+	if filter.To, err = parseTimeParam(query.Get("to")); err != nil {
+		return filter, errBadParam("to")
+	}
+
+	if v := query.Get("version"); v != "" {
+		version, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || version < 0 {
+			return filter, errBadParam("version")
+		}
+		filter.PackSetVersion = &version
+	}
+
+	return filter, nil
+}
+
+// parseTimeParam parses an optional RFC 3339 timestamp
+func parseTimeParam(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"order-pack-calculator/internal/model"
+	"order-pack-calculator/internal/storage"
+	"path/filepath"
+	"testing"
+)
+
+func newHistoryHandler(t *testing.T) *Handler {
+	t.Helper()
+
+	history, err := storage.NewOrderHistory(filepath.Join(t.TempDir(), "orders.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to open order history: %v", err)
+	}
+
+	handler := NewHandler([]int{250, 500, 1000, 2000, 5000})
+	handler.SetOrderHistory(history)
+	return handler
+}
+
+func calculateOrder(t *testing.T, handler *Handler, reqBody model.CalculateRequest) model.CalculateResponse {
+	t.Helper()
+
+	body, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.CalculatePacks(w, req)
+
+	var response model.CalculateResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return response
+}
+
+func TestCalculatePacksRecordsOrder(t *testing.T) {
+	handler := newHistoryHandler(t)
+
+	calculated := calculateOrder(t, handler, model.CalculateRequest{OrderQuantity: 251, ClientOrderID: "PO-1"})
+	if calculated.OrderID == "" {
+		t.Fatal("Expected order ID in response")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/orders/"+calculated.OrderID, nil)
+	req.SetPathValue("id", calculated.OrderID)
+	w := httptest.NewRecorder()
+	handler.GetOrder(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var rec model.OrderRecord
+	if err := json.NewDecoder(w.Body).Decode(&rec); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if rec.ClientOrderID != "PO-1" || rec.OrderQuantity != 251 || rec.TotalItems != 500 {
+		t.Errorf("Unexpected record: %+v", rec)
+	}
+	if len(rec.PackSizes) != 5 {
+		t.Errorf("Expected pack sizes used to be recorded, got %v", rec.PackSizes)
+	}
+}
+
+func TestListOrders(t *testing.T) {
+	handler := newHistoryHandler(t)
+	for _, qty := range []int{1, 251, 501} {
+		calculateOrder(t, handler, model.CalculateRequest{OrderQuantity: qty})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/orders?limit=2", nil)
+	w := httptest.NewRecorder()
+	handler.ListOrders(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var response model.OrderListResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Total != 3 || len(response.Orders) != 2 {
+		t.Errorf("Expected 2 of 3 orders, got %d of %d", len(response.Orders), response.Total)
+	}
+}
+
+func TestListOrdersInvalid(t *testing.T) {
+	handler := newHistoryHandler(t)
+
+	for _, query := range []string{"from=yesterday", "to=2024-13-01", "limit=0", "limit=abc", "offset=-1"} {
+		t.Run(query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/orders?"+query, nil)
+			w := httptest.NewRecorder()
+			handler.ListOrders(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestOrdersWithoutHistory(t *testing.T) {
+	handler := NewHandler([]int{250, 500})
+
+	if response := calculateOrder(t, handler, model.CalculateRequest{OrderQuantity: 251}); response.OrderID != "" {
+		t.Errorf("Expected no order ID without history, got %q", response.OrderID)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/orders", nil)
+	w := httptest.NewRecorder()
+	handler.ListOrders(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
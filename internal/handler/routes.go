@@ -0,0 +1,387 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"order-pack-calculator/internal/auth"
+	"order-pack-calculator/internal/model"
+	"order-pack-calculator/internal/openapi"
+	"order-pack-calculator/internal/router"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// API path prefixes
+const (
+	APIPrefix       = "/api/v1"
+	LegacyAPIPrefix = "/api" // Deprecated alias of APIPrefix
+)
+
+// OpenAPIPath is where the OpenAPI document of the API is served
+const OpenAPIPath = "/api/openapi.json"
+
+// endpoint describes an API endpoint for routing and the OpenAPI document
+type endpoint struct {
+	method   string
+	path     string // Relative to the version prefix
+	name     string // OpenAPI operation ID
+	summary  string
+	serve    func(*Handler, http.ResponseWriter, *http.Request)
+	request  any       // JSON request body, nil if none
+	response any       // JSON success response, nil if it has no body
+	status   int       // Success status, 200 if unset
+	role     auth.Role // Role an API key needs
+	csv      bool      // Request and response bodies are CSV instead
+	events   bool      // Response is a stream of Server-Sent Events with response as data
+	query    []openapi.Parameter
+	header   []openapi.Parameter
+}
+
+// Query parameters selecting recorded orders
+var (
+	selectionParams = []openapi.Parameter{
+		queryParam("from", "string", "Only orders recorded at or after this RFC 3339 time"),
+		queryParam("to", "string", "Only orders recorded before this RFC 3339 time"),
+		queryParam("sku", "string", "Only orders for this SKU"),
+		queryParam("version", "integer", "Only orders calculated with this pack set version"),
+	}
+	pageParams = []openapi.Parameter{
+		queryParam("limit", "integer", "Maximum number of orders to return (default 50, max 500)"),
+		queryParam("offset", "integer", "Number of orders to skip"),
+	}
+	auditParams = []openapi.Parameter{
+		queryParam("from", "string", "Only changes made at or after this RFC 3339 time"),
+		queryParam("to", "string", "Only changes made before this RFC 3339 time"),
+		queryParam("actor", "string", "Only changes made with the API key of this name, or anonymous"),
+		{
+			Name:        "action",
+			In:          "query",
+			Description: "Only changes of this kind",
+			Schema:      &openapi.Schema{Type: "string", Enum: AuditActions},
+		},
+		queryParam("limit", "integer", "Maximum number of changes to return (default 50, max 500)"),
+		queryParam("offset", "integer", "Number of changes to skip"),
+	}
+	bucketParam = openapi.Parameter{
+		Name:        "bucket",
+		In:          "query",
+		Description: "Size of the time series buckets (default week)",
+		Schema:      &openapi.Schema{Type: "string", Enum: []string{"day", "week", "month"}},
+	}
+	lastEventIDParam = openapi.Parameter{
+		Name:        "Last-Event-ID",
+		In:          "header",
+		Description: "ID of the last event received; the current pack sizes are only sent if they changed since",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+)
+
+// endpoints are the API endpoints of every version
+var endpoints = []endpoint{
+	{
+		method: http.MethodGet, path: "/packs", name: "getPackSizes",
+		summary:  "Get the current pack sizes",
+		role:     auth.RoleRead,
+		serve:    (*Handler).GetPackSizes,
+		response: model.PackSizesResponse{},
+	},
+	{
+		method: http.MethodGet, path: "/packs/events", name: "streamPackSizes",
+		summary:  "Stream the pack sizes as Server-Sent Events, one per change",
+		role:     auth.RoleRead,
+		serve:    (*Handler).PackEvents,
+		response: model.PackSizesResponse{},
+		events:   true,
+		header:   []openapi.Parameter{lastEventIDParam},
+	},
+	{
+		method: http.MethodPut, path: "/packs", name: "updatePackSizes",
+		summary:  "Replace the pack sizes",
+		role:     auth.RoleAdmin,
+		serve:    (*Handler).UpdatePackSizes,
+		request:  model.PackSizesRequest{},
+		response: model.PackSizesResponse{},
+	},
+	{
+		method: http.MethodPost, path: "/calculate", name: "calculatePacks",
+		summary:  "Calculate the packs for an order",
+		role:     auth.RoleRead,
+		serve:    (*Handler).CalculatePacks,
+		request:  model.CalculateRequest{},
+		response: model.CalculateResponse{},
+	},
+	{
+		method: http.MethodPost, path: "/calculate/csv", name: "calculatePacksCSV",
+		summary: "Calculate the packs for a CSV of order_id,quantity rows",
+		role:    auth.RoleRead,
+		serve:   (*Handler).CalculatePacksCSV,
+		csv:     true,
+	},
+	{
+		method: http.MethodGet, path: "/orders", name: "listOrders",
+		summary:  "List recorded calculations, newest first",
+		role:     auth.RoleRead,
+		serve:    (*Handler).ListOrders,
+		response: model.OrderListResponse{},
+		query:    append(append([]openapi.Parameter{}, selectionParams...), pageParams...),
+	},
+	{
+		method: http.MethodGet, path: "/orders/{id}", name: "getOrder",
+		summary:  "Get a recorded calculation",
+		role:     auth.RoleRead,
+		serve:    (*Handler).GetOrder,
+		response: model.OrderRecord{},
+	},
+	{
+		method: http.MethodGet, path: "/analytics", name: "getAnalytics",
+		summary:  "Aggregate recorded calculations",
+		role:     auth.RoleRead,
+		serve:    (*Handler).GetAnalytics,
+		response: model.AnalyticsResponse{},
+		query:    append(append([]openapi.Parameter{}, selectionParams...), bucketParam),
+	},
+	{
+		method: http.MethodGet, path: "/webhooks", name: "listWebhooks",
+		summary:  "List the webhook subscriptions",
+		role:     auth.RoleAdmin,
+		serve:    (*Handler).ListWebhooks,
+		response: model.WebhookListResponse{},
+	},
+	{
+		method: http.MethodPost, path: "/webhooks", name: "createWebhook",
+		summary:  "Subscribe a URL to events",
+		role:     auth.RoleAdmin,
+		serve:    (*Handler).CreateWebhook,
+		request:  model.WebhookRequest{},
+		response: model.WebhookSubscription{},
+		status:   http.StatusCreated,
+	},
+	{
+		method: http.MethodGet, path: "/webhooks/{id}", name: "getWebhook",
+		summary:  "Get a webhook subscription",
+		role:     auth.RoleAdmin,
+		serve:    (*Handler).GetWebhook,
+		response: model.WebhookSubscription{},
+	},
+	{
+		method: http.MethodDelete, path: "/webhooks/{id}", name: "deleteWebhook",
+		summary: "Remove a webhook subscription",
+		role:    auth.RoleAdmin,
+		serve:   (*Handler).DeleteWebhook,
+		status:  http.StatusNoContent,
+	},
+	{
+		method: http.MethodGet, path: "/webhooks/deliveries", name: "listWebhookDeliveries",
+		summary:  "List the most recent webhook deliveries, newest first",
+		role:     auth.RoleAdmin,
+		serve:    (*Handler).ListWebhookDeliveries,
+		response: model.WebhookDeliveryListResponse{},
+	},
+	{
+		method: http.MethodGet, path: "/webhooks/dead-letters", name: "listDeadLetters",
+		summary:  "List the webhook deliveries that failed every attempt, newest first",
+		role:     auth.RoleAdmin,
+		serve:    (*Handler).ListDeadLetters,
+		response: model.WebhookDeliveryListResponse{},
+	},
+	{
+		method: http.MethodPost, path: "/webhooks/dead-letters/{id}/redeliver", name: "redeliverDeadLetter",
+		summary:  "Deliver a dead letter again",
+		role:     auth.RoleAdmin,
+		serve:    (*Handler).RedeliverDeadLetter,
+		response: model.WebhookDelivery{},
+		status:   http.StatusAccepted,
+	},
+	{
+		method: http.MethodGet, path: "/audit", name: "listAudit",
+		summary:  "List administrative changes, newest first",
+		role:     auth.RoleAdmin,
+		serve:    (*Handler).ListAudit,
+		response: model.AuditListResponse{},
+		query:    auditParams,
+	},
+	{
+		method: http.MethodGet, path: "/metrics", name: "getMetrics",
+		summary:  "Get server metrics",
+		role:     auth.RoleRead,
+		serve:    (*Handler).GetMetrics,
+		response: model.MetricsResponse{},
+	},
+}
+
+// RegisterRoutes registers the API endpoints on a version of the router
+func (h *Handler) RegisterRoutes(v *router.Version) {
+	for _, e := range endpoints {
+		serve, role, keyInQuery := e.serve, e.role, e.events
+		route := v.Prefix() + e.path
+		v.Handle(e.method, e.path, func(w http.ResponseWriter, r *http.Request) {
+			start, method := time.Now(), r.Method
+			rec := &statusRecorder{ResponseWriter: w}
+			defer func() {
+				h.metrics.observeRequest(route, method, rec.status, time.Since(start))
+			}()
+
+			r, ok := h.authorize(rec, withCaller(r), role, keyInQuery)
+			if !ok {
+				return
+			}
+			serve(h, rec, r)
+		})
+	}
+}
+
+// NewRouter creates a router serving the API under APIPrefix, with the
+// unversioned legacy paths as deprecated aliases, its OpenAPI document at
+// OpenAPIPath, the Prometheus metrics at MetricsPath and the health, readiness
+// and version probes
+func (h *Handler) NewRouter() *router.Router {
+	r := router.New(
+		router.WithMethodNotAllowed(MethodNotAllowed),
+		router.WithNotFound(NotFound),
+		router.WithCORS("*", "Content-Type", APIKeyHeader, RequestIDHeader),
+		router.WithMiddleware(requestLogger),
+	)
+	h.RegisterRoutes(r.Version(APIPrefix))
+	r.Alias(LegacyAPIPrefix, APIPrefix, router.Deprecation{})
+
+	spec, _ := json.Marshal(OpenAPI(r.Routes()))
+	r.Handle(OpenAPIPath, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			sendError(w, errMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(spec)
+	}))
+
+	r.Handle(MetricsPath, http.HandlerFunc(h.ServeMetrics))
+	r.Handle(HealthPath, http.HandlerFunc(h.Healthz))
+	r.Handle(ReadinessPath, http.HandlerFunc(h.Readyz))
+	r.Handle(VersionPath, http.HandlerFunc(h.GetVersion))
+
+	return r
+}
+
+// pathParamPattern matches parameters in route paths, e.g. {id}
+var pathParamPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+// OpenAPI returns the OpenAPI document of the given API routes
+// Routes that are not API endpoints are left out.
+func OpenAPI(routes []router.Route) *openapi.Document {
+	doc := openapi.New("Order Pack Calculator API", strings.TrimPrefix(APIPrefix, "/api/"))
+	errorSchema := doc.SchemaFor(model.ErrorResponse{})
+	doc.Components.SecuritySchemes = map[string]*openapi.SecurityScheme{
+		"apiKey": {
+			Type:        "apiKey",
+			In:          "header",
+			Name:        APIKeyHeader,
+			Description: "Required when the server is configured with API keys",
+		},
+		"apiKeyQuery": {
+			Type:        "apiKey",
+			In:          "query",
+			Name:        apiKeyParam,
+			Description: "Alternative for event streams, as EventSource cannot send headers",
+		},
+	}
+
+	for _, route := range routes {
+		prefix := APIPrefix
+		if route.Deprecated {
+			prefix = LegacyAPIPrefix
+		}
+
+		e, ok := findEndpoint(route.Method, strings.TrimPrefix(route.Path, prefix))
+		if !ok {
+			continue
+		}
+
+		op := &openapi.Operation{
+			Summary:     e.summary,
+			Description: fmt.Sprintf("Requires an API key with the %s role when API keys are configured.", e.role),
+			OperationID: e.name,
+			Deprecated:  route.Deprecated,
+			Parameters:  append(append([]openapi.Parameter{}, e.query...), e.header...),
+			Responses: map[string]openapi.Response{
+				"default": {
+					Description: "Error",
+					Content:     map[string]openapi.MediaType{problemContentType: {Schema: errorSchema}},
+				},
+			},
+			Security: []openapi.SecurityRequirement{{"apiKey": {}}},
+		}
+		if e.events {
+			op.Security = append(op.Security, openapi.SecurityRequirement{"apiKeyQuery": {}})
+		}
+		if route.Deprecated {
+			op.OperationID += "Legacy"
+		}
+
+		for _, match := range pathParamPattern.FindAllStringSubmatch(route.Path, -1) {
+			op.Parameters = append(op.Parameters, openapi.Parameter{
+				Name:     match[1],
+				In:       "path",
+				Required: true,
+				Schema:   &openapi.Schema{Type: "string"},
+			})
+		}
+
+		switch {
+		case e.csv:
+			csvContent := map[string]openapi.MediaType{"text/csv": {Schema: &openapi.Schema{Type: "string"}}}
+			op.RequestBody = &openapi.RequestBody{Required: true, Content: csvContent}
+			op.Responses["200"] = openapi.Response{Description: "Success", Content: csvContent}
+		case e.events:
+			op.Responses["200"] = openapi.Response{
+				Description: "Stream of pack_sizes events whose data is a " +
+					strings.TrimPrefix(doc.SchemaFor(e.response).Ref, "#/components/schemas/"),
+				Content: map[string]openapi.MediaType{"text/event-stream": {Schema: &openapi.Schema{Type: "string"}}},
+			}
+		default:
+			if e.request != nil {
+				op.RequestBody = &openapi.RequestBody{
+					Required: true,
+					Content:  map[string]openapi.MediaType{"application/json": {Schema: doc.SchemaFor(e.request)}},
+				}
+			}
+			response := openapi.Response{Description: "Success"}
+			if e.response != nil {
+				response.Content = map[string]openapi.MediaType{"application/json": {Schema: doc.SchemaFor(e.response)}}
+			}
+			status := e.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			op.Responses[strconv.Itoa(status)] = response
+		}
+
+		doc.AddOperation(route.Method, route.Path, op)
+	}
+
+	return doc
+}
+
+// findEndpoint returns the endpoint for a method and version-relative path
+func findEndpoint(method, path string) (endpoint, bool) {
+	for _, e := range endpoints {
+		if e.method == method && e.path == path {
+			return e, true
+		}
+	}
+	return endpoint{}, false
+}
+
+// queryParam describes an optional query parameter
+func queryParam(name, schemaType, description string) openapi.Parameter {
+	return openapi.Parameter{
+		Name:        name,
+		In:          "query",
+		Description: description,
+		Schema:      &openapi.Schema{Type: schemaType},
+	}
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterServesVersionedAndLegacyPaths(t *testing.T) {
+	r := NewHandler([]int{250, 500}).NewRouter()
+
+	for _, path := range []string{APIPrefix + "/packs", LegacyAPIPrefix + "/packs"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: expected status 200, got %d", path, w.Code)
+		}
+
+		deprecated := path == LegacyAPIPrefix+"/packs"
+		if got := w.Header().Get("Deprecation") != ""; got != deprecated {
+			t.Errorf("%s: expected deprecated %v, got %v", path, deprecated, got)
+		}
+	}
+
+	// Unsupported methods get a problem response
+	req := httptest.NewRequest(http.MethodDelete, APIPrefix+"/packs", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	problem := decodeProblem(t, w, http.StatusMethodNotAllowed)
+	if problem.Code != CodeMethodNotAllowed {
+		t.Errorf("Expected code %s, got %s", CodeMethodNotAllowed, problem.Code)
+	}
+}
+
+func TestRouterUnknownAPIPaths(t *testing.T) {
+	r := NewHandler([]int{250, 500}).NewRouter()
+	r.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("static"))
+	}))
+
+	// Unknown API paths get a problem response instead of the static files
+	for _, path := range []string{APIPrefix + "/missing", LegacyAPIPrefix + "/orders/1/missing"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		problem := decodeProblem(t, w, http.StatusNotFound)
+		if problem.Code != CodeNotFound {
+			t.Errorf("%s: expected code %s, got %s", path, CodeNotFound, problem.Code)
+		}
+	}
+
+	for _, path := range []string{"/index.html", OpenAPIPath} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: expected status 200, got %d", path, w.Code)
+		}
+	}
+}
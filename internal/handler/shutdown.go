@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// Drain prepares the handler for shutdown: readiness checks start failing
+// so load balancers stop sending traffic, and event streams end so they do
+// not hold the server open. Requests keep being served.
+func (h *Handler) Drain() {
+	if h.draining.Swap(true) {
+		return
+	}
+	h.events.close()
+	slog.Info("Draining: reporting not ready and closing event streams")
+}
+
+// Close lets webhook deliveries finish until ctx is done, logging those
+// that could not be made, then flushes storage, order history and audit
+// log to disk. Call it once the server no longer serves requests.
+func (h *Handler) Close(ctx context.Context) error {
+	h.Drain()
+	for _, delivery := range h.webhooks.Close(ctx) {
+		slog.Warn("Webhook not delivered before shutdown",
+			"delivery_id", delivery.ID,
+			"webhook_id", delivery.WebhookID,
+			"event", delivery.Event,
+			"attempts", delivery.Attempts,
+			"error", delivery.Error,
+		)
+	}
+
+	var errs []error
+	if h.storage != nil {
+		if err := h.storage.Sync(); err != nil {
+			h.metrics.storageError(opSavePackSizes)
+			errs = append(errs, err)
+		}
+	}
+	if h.history != nil {
+		if err := h.history.Sync(); err != nil {
+			h.metrics.storageError(opRecordOrder)
+			errs = append(errs, err)
+		}
+	}
+	if err := h.audit.Sync(); err != nil {
+		h.metrics.storageError(opRecordAudit)
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
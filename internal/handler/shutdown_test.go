@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"order-pack-calculator/internal/model"
+	"order-pack-calculator/internal/storage"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDrain(t *testing.T) {
+	h := NewHandler([]int{250, 500})
+	server := httptest.NewServer(h.NewRouter())
+	t.Cleanup(server.Close)
+
+	stream := openEventStream(t, server, "")
+	readEvent(t, stream)
+
+	h.Drain()
+	h.Drain() // Draining twice is harmless
+
+	// Open event streams end
+	ended := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, stream)
+		close(ended)
+	}()
+	select {
+	case <-ended:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the event stream to end when draining")
+	}
+
+	// Readiness fails while other requests are still served
+	var response model.HealthResponse
+	probe(t, h, ReadinessPath, http.StatusServiceUnavailable, &response)
+	if len(response.Checks) != 2 || response.Checks[1].Name != "shutdown" || response.Checks[1].Status != "failed" {
+		t.Errorf("Expected a failed shutdown check, got %+v", response.Checks)
+	}
+	probe(t, h, HealthPath, http.StatusOK, &response)
+
+	w := httptest.NewRecorder()
+	h.NewRouter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, APIPrefix+"/packs", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 while draining, got %d", w.Code)
+	}
+}
+
+func TestClose(t *testing.T) {
+	dir := t.TempDir()
+	h := NewHandlerWithStorage([]int{250, 500}, storage.NewStorage(filepath.Join(dir, "packs.json")))
+	history, err := storage.NewOrderHistory(filepath.Join(dir, "orders.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to open order history: %v", err)
+	}
+	h.SetOrderHistory(history)
+	audit, err := storage.NewAuditLog(filepath.Join(dir, "audit.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	h.SetAuditLog(audit)
+
+	calculateOrder(t, h, model.CalculateRequest{OrderQuantity: 251})
+	if _, err := h.SetPackSizes(context.Background(), []int{100, 200}); err != nil {
+		t.Fatalf("SetPackSizes failed: %v", err)
+	}
+
+	if err := h.Close(context.Background()); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if !h.draining.Load() {
+		t.Error("Expected Close to drain the handler")
+	}
+
+	// Everything written before closing is on disk
+	reopened, _ := storage.NewOrderHistory(filepath.Join(dir, "orders.jsonl"))
+	if _, total, _ := reopened.List(storage.OrderFilter{}); total != 1 {
+		t.Errorf("Expected 1 recorded order, got %d", total)
+	}
+	if sizes, _ := storage.NewStorage(filepath.Join(dir, "packs.json")).LoadPackSizes(); len(sizes) != 2 {
+		t.Errorf("Expected the saved pack sizes, got %v", sizes)
+	}
+}
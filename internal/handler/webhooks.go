@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"order-pack-calculator/internal/model"
+	"order-pack-calculator/internal/webhook"
+	"slices"
+)
+
+// SetWebhooks enables webhook subscriptions, notifying d of pack size
+// changes and calculations
+func (h *Handler) SetWebhooks(d *webhook.Dispatcher) {
+	h.webhooks = d
+}
+
+// ListWebhooks returns the webhook subscriptions
+func (h *Handler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendError(w, errMethodNotAllowed)
+		return
+	}
+
+	if h.webhooks == nil {
+		sendError(w, errWebhooksDisabled)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.WebhookListResponse{Webhooks: h.webhooks.Webhooks()})
+}
+
+// CreateWebhook subscribes a URL to events
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendError(w, errMethodNotAllowed)
+		return
+	}
+
+	if h.webhooks == nil {
+		sendError(w, errWebhooksDisabled)
+		return
+	}
+
+	var req model.WebhookRequest
+	if err := decodeJSON(w, r, &req, "url", "events", "secret"); err != nil {
+		sendError(w, err)
+		return
+	}
+
+	if err := validateWebhook(req); err != nil {
+		sendError(w, err)
+		return
+	}
+
+	sub, err := h.webhooks.Add(req.URL, req.Events, req.Secret)
+	if err != nil {
+		h.metrics.storageError(opSaveWebhooks)
+		slog.ErrorContext(r.Context(), "Failed to save webhook", "error", err)
+		sendError(w, errWebhookSave)
+		return
+	}
+	h.recordAudit(r.Context(), AuditWebhookCreated, sub.ID, nil, sub)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sub)
+}
+
+// GetWebhook returns a webhook subscription by ID
+func (h *Handler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendError(w, errMethodNotAllowed)
+		return
+	}
+
+	if h.webhooks == nil {
+		sendError(w, errWebhooksDisabled)
+		return
+	}
+
+	sub, err := h.webhooks.Webhook(r.PathValue("id"))
+	if err != nil {
+		sendError(w, errWebhookNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sub)
+}
+
+// DeleteWebhook removes a webhook subscription
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		sendError(w, errMethodNotAllowed)
+		return
+	}
+
+	if h.webhooks == nil {
+		sendError(w, errWebhooksDisabled)
+		return
+	}
+
+	id := r.PathValue("id")
+	sub, _ := h.webhooks.Webhook(id)
+	switch err := h.webhooks.Remove(id); err {
+	case nil:
+		h.recordAudit(r.Context(), AuditWebhookDeleted, id, sub, nil)
+		w.WriteHeader(http.StatusNoContent)
+	case webhook.ErrNotFound:
+		sendError(w, errWebhookNotFound)
+	default:
+		h.metrics.storageError(opSaveWebhooks)
+		slog.ErrorContext(r.Context(), "Failed to save webhooks", "error", err)
+		sendError(w, errWebhookSave)
+	}
+}
+
+// ListWebhookDeliveries returns the most recent webhook deliveries, newest
+// first
+func (h *Handler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendError(w, errMethodNotAllowed)
+		return
+	}
+
+	if h.webhooks == nil {
+		sendError(w, errWebhooksDisabled)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.WebhookDeliveryListResponse{Deliveries: h.webhooks.Deliveries()})
+}
+
+// ListDeadLetters returns the webhook deliveries that failed every attempt,
+// newest first
+func (h *Handler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendError(w, errMethodNotAllowed)
+		return
+	}
+
+	if h.webhooks == nil {
+		sendError(w, errWebhooksDisabled)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.WebhookDeliveryListResponse{Deliveries: h.webhooks.DeadLetters()})
+}
+
+// RedeliverDeadLetter delivers a dead letter again
+// The delivery is retried in the background; its progress shows in the
+// delivery log.
+func (h *Handler) RedeliverDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendError(w, errMethodNotAllowed)
+		return
+	}
+
+	if h.webhooks == nil {
+		sendError(w, errWebhooksDisabled)
+		return
+	}
+
+	id := r.PathValue("id")
+	delivery, err := h.webhooks.Redeliver(id)
+	switch err {
+	case nil:
+		h.recordAudit(r.Context(), AuditDeadLetterRedelivered, id, nil, delivery)
+	case webhook.ErrNotFound:
+		sendError(w, errDeadLetterNotFound)
+		return
+	default:
+		sendError(w, errWebhookRemoved)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(delivery)
+}
+
+// validateWebhook checks that a webhook has an absolute HTTP(S) URL, known
+// events and a secret. Every problem is listed in the returned error.
+func validateWebhook(req model.WebhookRequest) error {
+	var fieldErrors []model.FieldError
+
+	if u, err := url.Parse(req.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		fieldErrors = append(fieldErrors, model.FieldError{
+			Field:   "url",
+			Code:    CodeInvalidURL,
+			Message: "URL must be an absolute http or https URL",
+		})
+	}
+
+	if len(req.Events) == 0 {
+		fieldErrors = append(fieldErrors, model.FieldError{
+			Field:   "events",
+			Code:    CodeInvalidEvent,
+			Message: fmt.Sprintf("Events must list at least one of %v", webhook.Events),
+		})
+	}
+	for i, event := range req.Events {
+		if !slices.Contains(webhook.Events, event) {
+			fieldErrors = append(fieldErrors, model.FieldError{
+				Field:   fmt.Sprintf("events[%d]", i),
+				Code:    CodeInvalidEvent,
+				Message: fmt.Sprintf("Event must be one of %v", webhook.Events),
+			})
+		}
+	}
+
+	if req.Secret == "" {
+		fieldErrors = append(fieldErrors, model.FieldError{
+			Field:   "secret",
+			Code:    CodeMissingSecret,
+			Message: "Secret must not be empty",
+		})
+	}
+
+	if len(fieldErrors) > 0 {
+		problem := newFieldProblem(http.StatusBadRequest, CodeInvalidWebhook, fieldErrors[0].Field,
+			"Invalid webhook subscription")
+		problem.errors = fieldErrors
+		return problem
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"order-pack-calculator/internal/model"
+	"order-pack-calculator/internal/webhook"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newWebhookHandler creates a handler with webhooks enabled
+func newWebhookHandler(t *testing.T) (*Handler, *webhook.Dispatcher) {
+	t.Helper()
+
+	dispatcher, err := webhook.New(nil, webhook.WithRetries(2, time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create dispatcher: %v", err)
+	}
+	t.Cleanup(func() { dispatcher.Close(context.Background()) })
+
+	handler := NewHandler([]int{250, 500, 1000})
+	handler.SetWebhooks(dispatcher)
+	return handler, dispatcher
+}
+
+// serveAPI sends a request through the API router
+func serveAPI(handler *Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, APIPrefix+path, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.NewRouter().ServeHTTP(w, req)
+	return w
+}
+
+func TestWebhookSubscriptions(t *testing.T) {
+	handler, _ := newWebhookHandler(t)
+
+	w := serveAPI(handler, http.MethodPost, "/webhooks",
+		`{"url": "https://erp.example.com/hooks", "events": ["pack_sizes.updated"], "secret": "s3cret"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "s3cret") {
+		t.Error("Expected the secret not to be sent back")
+	}
+
+	var created model.WebhookSubscription
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if created.ID == "" || created.URL != "https://erp.example.com/hooks" {
+		t.Errorf("Unexpected subscription: %+v", created)
+	}
+
+	w = serveAPI(handler, http.MethodGet, "/webhooks/"+created.ID, "")
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+
+	w = serveAPI(handler, http.MethodGet, "/webhooks", "")
+	var list model.WebhookListResponse
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(list.Webhooks) != 1 {
+		t.Errorf("Expected 1 webhook, got %d", len(list.Webhooks))
+	}
+
+	w = serveAPI(handler, http.MethodDelete, "/webhooks/"+created.ID, "")
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+
+	w = serveAPI(handler, http.MethodGet, "/webhooks/"+created.ID, "")
+	if problem := decodeProblem(t, w, http.StatusNotFound); problem.Code != CodeWebhookNotFound {
+		t.Errorf("Expected code %s, got %s", CodeWebhookNotFound, problem.Code)
+	}
+}
+
+func TestCreateWebhookInvalid(t *testing.T) {
+	handler, _ := newWebhookHandler(t)
+
+	w := serveAPI(handler, http.MethodPost, "/webhooks",
+		`{"url": "/relative", "events": ["pack_sizes.updated", "unknown"], "secret": ""}`)
+	problem := decodeProblem(t, w, http.StatusBadRequest)
+
+	if problem.Code != CodeInvalidWebhook {
+		t.Errorf("Expected code %s, got %s", CodeInvalidWebhook, problem.Code)
+	}
+
+	var fields []string
+	for _, fieldErr := range problem.Errors {
+		fields = append(fields, fieldErr.Field+":"+fieldErr.Code)
+	}
+	if got, want := strings.Join(fields, ","), "url:invalid_url,events[1]:invalid_event,secret:missing_secret"; got != want {
+		t.Errorf("Expected field errors %s, got %s", want, got)
+	}
+}
+
+func TestWebhooksDisabled(t *testing.T) {
+	handler := NewHandler([]int{250})
+
+	w := serveAPI(handler, http.MethodGet, "/webhooks", "")
+	if problem := decodeProblem(t, w, http.StatusNotFound); problem.Code != CodeWebhooksDisabled {
+		t.Errorf("Expected code %s, got %s", CodeWebhooksDisabled, problem.Code)
+	}
+}
+
+func TestWebhooksNotified(t *testing.T) {
+	type received struct {
+		event string
+		body  []byte
+		sig   string
+		ts    int64
+	}
+	requests := make(chan received, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ts, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		requests <- received{r.Header.Get(webhook.HeaderEvent), body, r.Header.Get(webhook.HeaderSignature), ts}
+	}))
+	defer receiver.Close()
+
+	handler, dispatcher := newWebhookHandler(t)
+	if _, err := dispatcher.Add(receiver.URL, webhook.Events, "s3cret"); err != nil {
+		t.Fatalf("Failed to add webhook: %v", err)
+	}
+
+	if _, err := handler.SetPackSizes(context.Background(), []int{23, 31, 53}); err != nil {
+		t.Fatalf("SetPackSizes failed: %v", err)
+	}
+	if w := serveAPI(handler, http.MethodPost, "/calculate", `{"order_quantity": 500000}`); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	// Deliveries run concurrently, so they may arrive in either order
+	got := map[string]received{}
+	for len(got) < 2 {
+		select {
+		case req := <-requests:
+			got[req.event] = req
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for webhooks, got %d", len(got))
+		}
+	}
+
+	for event, req := range got {
+		if req.sig != webhook.Sign("s3cret", req.ts, req.body) {
+			t.Errorf("%s: invalid signature", event)
+		}
+	}
+
+	var updated struct {
+		Data model.PackSizesResponse `json:"data"`
+	}
+	json.Unmarshal(got[webhook.EventPackSizesUpdated].body, &updated)
+	if updated.Data.Version != 1 || len(updated.Data.PackSizes) != 3 {
+		t.Errorf("Expected pack sizes [23 31 53] at version 1, got %+v", updated.Data)
+	}
+
+	var calculated struct {
+		Data model.CalculateResponse `json:"data"`
+	}
+	json.Unmarshal(got[webhook.EventOrderCalculated].body, &calculated)
+	if calculated.Data.TotalItems != 500000 {
+		t.Errorf("Expected 500000 items, got %d", calculated.Data.TotalItems)
+	}
+}
@@ -0,0 +1,93 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNewJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "info", "json")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	logger.Debug("hidden")
+	logger.InfoContext(WithRequestID(context.Background(), "req-1"), "Pack sizes updated", "version", 3)
+	logger.With("component", "grpc").WarnContext(WithRequestID(context.Background(), "req-2"), "Slow call")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 records above the debug level, got %d: %s", len(lines), buf.String())
+	}
+
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("Expected JSON records, got %s", lines[0])
+	}
+	if record["msg"] != "Pack sizes updated" || record["level"] != "INFO" || record["version"] != float64(3) || record[RequestIDKey] != "req-1" {
+		t.Errorf("Unexpected record: %v", record)
+	}
+
+	json.Unmarshal([]byte(lines[1]), &record)
+	if record["component"] != "grpc" || record[RequestIDKey] != "req-2" {
+		t.Errorf("Expected the request ID on derived loggers, got %v", record)
+	}
+}
+
+func TestNewText(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "DEBUG", "text")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	logger.Debug("Reloaded pack sizes", "version", 4)
+	if got := buf.String(); !strings.Contains(got, `level=DEBUG msg="Reloaded pack sizes" version=4`) {
+		t.Errorf("Unexpected text record: %s", got)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	if _, err := New(&bytes.Buffer{}, "verbose", "json"); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+	if _, err := New(&bytes.Buffer{}, "info", "xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	if id := RequestID(context.Background()); id != "" {
+		t.Errorf("Expected no request ID, got %q", id)
+	}
+	if a, b := NewRequestID(), NewRequestID(); len(a) != 16 || a == b {
+		t.Errorf("Expected distinct 16 character IDs, got %q and %q", a, b)
+	}
+}
+
+func TestValidRequestID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"req-7", true},
+		{"3f2a9c1e.b_X", true},
+		{strings.Repeat("a", 128), true},
+		{"", false},
+		{strings.Repeat("a", 129), false},
+		{"with space", false},
+		{"line\nbreak", false},
+		{`quote"`, false},
+		{"ünicode", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidRequestID(tt.id); got != tt.want {
+			t.Errorf("ValidRequestID(%q): expected %v, got %v", tt.id, tt.want, got)
+		}
+	}
+}
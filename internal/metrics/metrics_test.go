@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExposition(t *testing.T) {
+	r := NewRegistry()
+
+	requests := r.NewCounterVec("requests_total", "Requests served.", "route", "status")
+	requests.Inc("/packs", "200")
+	requests.Add(2, "/calculate", "400")
+	requests.Inc("/packs", "200")
+
+	latency := r.NewHistogramVec("latency_seconds", "Request latency.", []float64{0.1, 1}, "route")
+	latency.Observe(0.05, "/packs")
+	latency.Observe(0.1, "/packs")
+	latency.Observe(3, "/packs")
+
+	r.NewGaugeFunc("version", "Pack set version.", func() float64 { return 7 })
+
+	var b strings.Builder
+	if _, err := r.WriteTo(&b); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+
+	want := `# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{route="/calculate",status="400"} 2
+requests_total{route="/packs",status="200"} 2
+# HELP latency_seconds Request latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/packs",le="0.1"} 2
+latency_seconds_bucket{route="/packs",le="1"} 2
+latency_seconds_bucket{route="/packs",le="+Inf"} 3
+latency_seconds_sum{route="/packs"} 3.15
+latency_seconds_count{route="/packs"} 3
+# HELP version Pack set version.
+# TYPE version gauge
+version 7
+`
+	if b.String() != want {
+		t.Errorf("Unexpected exposition:\n%s\nwant:\n%s", b.String(), want)
+	}
+
+	if v := requests.Value("/packs", "200"); v != 2 {
+		t.Errorf("Expected value 2, got %v", v)
+	}
+	if n := latency.Count("/packs"); n != 3 {
+		t.Errorf("Expected 3 observations, got %d", n)
+	}
+}
+
+func TestEscaping(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("errors_total", "Errors by \"reason\"\nand more.", "reason").Inc("bad \"quote\"\\\n")
+
+	var b strings.Builder
+	r.WriteTo(&b)
+
+	if !strings.Contains(b.String(), `# HELP errors_total Errors by "reason"\nand more.`) {
+		t.Errorf("Expected escaped help, got %s", b.String())
+	}
+	if !strings.Contains(b.String(), `errors_total{reason="bad \"quote\"\\\n"} 1`) {
+		t.Errorf("Expected escaped label value, got %s", b.String())
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterFunc("hits_total", "Cache hits.", func() float64 { return 3 })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if got := w.Header().Get("Content-Type"); got != ContentType {
+		t.Errorf("Expected content type %s, got %s", ContentType, got)
+	}
+	if !strings.Contains(w.Body.String(), "# TYPE hits_total counter\nhits_total 3\n") {
+		t.Errorf("Unexpected body: %s", w.Body.String())
+	}
+}
+
+func TestInvalidRegistrations(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(r *Registry)
+	}{
+		{"invalid name", func(r *Registry) { r.NewCounterVec("requests-total", "") }},
+		{"invalid label", func(r *Registry) { r.NewCounterVec("requests_total", "", "1route") }},
+		{"reserved label", func(r *Registry) { r.NewHistogramVec("latency", "", DefaultBuckets, "le") }},
+		{"unsorted buckets", func(r *Registry) { r.NewHistogramVec("latency", "", []float64{1, 0.1}) }},
+		{"wrong label count", func(r *Registry) { r.NewCounterVec("requests_total", "", "route").Inc() }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected a panic")
+				}
+			}()
+			tt.register(NewRegistry())
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 // PackSizesRequest represents a request to update pack sizes
 type PackSizesRequest struct {
 	PackSizes []int `json:"pack_sizes"`
@@ -8,12 +10,15 @@ type PackSizesRequest struct {
 // PackSizesResponse represents pack sizes data
 type PackSizesResponse struct {
 	PackSizes []int  `json:"pack_sizes"`
+	Version   int64  `json:"version"`
 	Message   string `json:"message,omitempty"`
 }
 
 // CalculateRequest represents a request to calculate optimal packs
 type CalculateRequest struct {
-	OrderQuantity int `json:"order_quantity"`
+	OrderQuantity int    `json:"order_quantity"`
+	ClientOrderID string `json:"client_order_id,omitempty"`
+	SKU           string `json:"sku,omitempty"`
 }
 
 // PackBreakdown represents a single pack size and its quantity
@@ -24,13 +29,186 @@ type PackBreakdown struct {
 
 // CalculateResponse represents the result of pack calculation
 type CalculateResponse struct {
+	OrderID       string          `json:"order_id,omitempty"` // Set when order history is enabled
+	ClientOrderID string          `json:"client_order_id,omitempty"`
+	SKU           string          `json:"sku,omitempty"`
 	OrderQuantity int             `json:"order_quantity"`
 	Packs         []PackBreakdown `json:"packs"`
 	TotalItems    int             `json:"total_items"`
 	TotalPacks    int             `json:"total_packs"`
 }
 
-// ErrorResponse represents an error message
+// OrderRecord represents a recorded calculation result
+type OrderRecord struct {
+	ID             string          `json:"id"`
+	ClientOrderID  string          `json:"client_order_id,omitempty"`
+	SKU            string          `json:"sku,omitempty"`
+	OrderQuantity  int             `json:"order_quantity"`
+	PackSetVersion int64           `json:"pack_set_version"`
+	PackSizes      []int           `json:"pack_sizes"`
+	Packs          []PackBreakdown `json:"packs"`
+	TotalItems     int             `json:"total_items"`
+	TotalPacks     int             `json:"total_packs"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// OrderListResponse represents a page of recorded orders
+type OrderListResponse struct {
+	Orders []OrderRecord `json:"orders"`
+	Total  int           `json:"total"` // Matching orders before pagination
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+}
+
+// ErrorResponse represents an error as an RFC 7807 problem
+// Error repeats Detail for clients written before the problem fields.
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Type   string       `json:"type,omitempty"`
+	Title  string       `json:"title,omitempty"`
+	Status int          `json:"status,omitempty"`
+	Detail string       `json:"detail,omitempty"`
+	Error  string       `json:"error"`
+	Code   string       `json:"code,omitempty"`   // Stable, machine-readable error code
+	Field  string       `json:"field,omitempty"`  // Path of the offending field, e.g. "pack_sizes[1]"
+	Errors []FieldError `json:"errors,omitempty"` // Every validation problem found
+}
+
+// FieldError represents a single validation problem
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// WebhookRequest represents a request to subscribe a URL to events
+type WebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"` // Key for the HMAC signature of every delivery
+}
+
+// WebhookSubscription represents a URL subscribed to events
+// The secret is never sent back.
+type WebhookSubscription struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookListResponse represents the webhook subscriptions
+type WebhookListResponse struct {
+	Webhooks []WebhookSubscription `json:"webhooks"`
+}
+
+// WebhookEvent represents the body sent to a webhook
+type WebhookEvent struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"` // e.g. pack_sizes.updated
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"` // PackSizesResponse or CalculateResponse, depending on the event
+}
+
+// WebhookDelivery represents the delivery of an event to a webhook
+type WebhookDelivery struct {
+	ID             string       `json:"id"`
+	WebhookID      string       `json:"webhook_id"`
+	URL            string       `json:"url"`
+	Event          string       `json:"event"`
+	Status         string       `json:"status"` // pending, delivered or failed
+	Attempts       int          `json:"attempts"`
+	ResponseStatus int          `json:"response_status,omitempty"` // HTTP status of the last attempt
+	Error          string       `json:"error,omitempty"`           // Why the last attempt failed
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	Payload        WebhookEvent `json:"payload"`
+}
+
+// WebhookDeliveryListResponse represents webhook deliveries, newest first
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
+
+// AnalyticsSummary aggregates recorded orders
+type AnalyticsSummary struct {
+	Orders               int         `json:"orders"`
+	ItemsRequested       int         `json:"items_requested"`
+	ItemsShipped         int         `json:"items_shipped"`
+	ExcessItems          int         `json:"excess_items"`
+	TotalPacks           int         `json:"total_packs"`
+	PacksBySize          map[int]int `json:"packs_by_size"`
+	AverageExcessPercent float64     `json:"average_excess_percent"` // Mean of per-order excess relative to quantity
+}
+
+// AnalyticsBucket aggregates recorded orders within one time bucket
+type AnalyticsBucket struct {
+	Start time.Time `json:"start"`
+	AnalyticsSummary
+}
+
+// AnalyticsResponse represents totals and a time series over recorded orders
+type AnalyticsResponse struct {
+	Bucket string            `json:"bucket"` // day, week or month
+	Totals AnalyticsSummary  `json:"totals"`
+	Series []AnalyticsBucket `json:"series"`
+}
+
+// CacheStats represents the counters of the calculation result cache
+type CacheStats struct {
+	Enabled    bool  `json:"enabled"`
+	Hits       int64 `json:"hits"`
+	Misses     int64 `json:"misses"`
+	Evictions  int64 `json:"evictions"`
+	Entries    int   `json:"entries"`
+	Bytes      int64 `json:"bytes"` // Approximate memory held by entries
+	MaxEntries int   `json:"max_entries"`
+	MaxBytes   int64 `json:"max_bytes"`
+}
+
+// MetricsResponse represents server metrics
+type MetricsResponse struct {
+	Cache CacheStats `json:"cache"`
+}
+
+// AuditRecord represents an administrative change in the audit log
+type AuditRecord struct {
+	ID        string    `json:"id"`
+	Action    string    `json:"action"`             // e.g. pack_sizes.updated
+	Resource  string    `json:"resource,omitempty"` // ID of the changed webhook or dead letter
+	Actor     string    `json:"actor"`              // Name of the API key, or anonymous
+	SourceIP  string    `json:"source_ip,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	OldValue  any       `json:"old_value,omitempty"`
+	NewValue  any       `json:"new_value,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuditListResponse represents a page of audit records
+type AuditListResponse struct {
+	Records []AuditRecord `json:"records"`
+	Total   int           `json:"total"` // Matching records before pagination
+	Limit   int           `json:"limit"`
+	Offset  int           `json:"offset"`
+}
+
+// HealthCheck represents the result of one readiness check
+type HealthCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // ok or failed
+	Error  string `json:"error,omitempty"`
+}
+
+// HealthResponse represents whether the server is alive or ready
+type HealthResponse struct {
+	Status string        `json:"status"` // ok or unavailable
+	Checks []HealthCheck `json:"checks,omitempty"`
+}
+
+// VersionResponse represents the build the server runs
+type VersionResponse struct {
+	Version    string `json:"version"`
+	Commit     string `json:"commit,omitempty"`
+	CommitTime string `json:"commit_time,omitempty"`
+	Modified   bool   `json:"modified,omitempty"` // Built with uncommitted changes
+	GoVersion  string `json:"go_version"`
 }
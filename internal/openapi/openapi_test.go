@@ -0,0 +1,88 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testSummary struct {
+	Count int `json:"count"`
+}
+
+type testRecord struct {
+	ID      string      `json:"id"`
+	Note    string      `json:"note,omitempty"`
+	Parent  *int64      `json:"parent"`
+	Tags    []string    `json:"tags"`
+	Counts  map[int]int `json:"counts"`
+	Created time.Time   `json:"created"`
+	Ignored string      `json:"-"`
+	hidden  string
+	testSummary
+}
+
+func TestSchemaFor(t *testing.T) {
+	doc := New("Test", "v1")
+
+	ref := doc.SchemaFor(testRecord{})
+	if ref.Ref != "#/components/schemas/testRecord" {
+		t.Fatalf("Expected a component reference, got %+v", ref)
+	}
+
+	s := doc.Components.Schemas["testRecord"]
+	wantRequired := []string{"id", "parent", "tags", "counts", "created", "count"}
+	if !reflect.DeepEqual(s.Required, wantRequired) {
+		t.Errorf("Required = %v, want %v", s.Required, wantRequired)
+	}
+
+	wantTypes := map[string]string{
+		"id": "string", "note": "string", "parent": "integer", "tags": "array",
+		"counts": "object", "created": "string", "count": "integer",
+	}
+	if len(s.Properties) != len(wantTypes) {
+		t.Errorf("Expected %d properties, got %v", len(wantTypes), s.Properties)
+	}
+	for name, want := range wantTypes {
+		if prop := s.Properties[name]; prop == nil || prop.Type != want {
+			t.Errorf("Property %s = %+v, want type %s", name, prop, want)
+		}
+	}
+	if !s.Properties["parent"].Nullable || s.Properties["created"].Format != "date-time" {
+		t.Errorf("Unexpected parent or created schema: %+v, %+v", s.Properties["parent"], s.Properties["created"])
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	doc := New("Test", "v1")
+	schema := doc.SchemaFor(testRecord{})
+
+	valid := `{"id":"a","parent":null,"tags":["x"],"counts":{"5":1},"created":"2026-01-02T03:04:05Z","count":2}`
+	if err := doc.ValidateJSON(schema, []byte(valid)); err != nil {
+		t.Errorf("ValidateJSON() of a valid record error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"missing property", `{"id":"a","parent":1,"tags":[],"counts":{},"created":"2026-01-02T03:04:05Z"}`, `missing required property "count"`},
+		{"undocumented property", strings.Replace(valid, `"count":2`, `"count":2,"extra":true`, 1), `undocumented property "extra"`},
+		{"wrong type", strings.Replace(valid, `"count":2`, `"count":"2"`, 1), "$.count: expected integer"},
+		{"fraction", strings.Replace(valid, `"count":2`, `"count":2.5`, 1), "$.count: expected integer"},
+		{"wrong item", strings.Replace(valid, `["x"]`, `[1]`, 1), "$.tags[0]: expected string"},
+		{"bad time", strings.Replace(valid, "2026-01-02T03:04:05Z", "yesterday", 1), "invalid date-time"},
+		{"null", strings.Replace(valid, `"id":"a"`, `"id":null`, 1), "$.id: null is not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := doc.ValidateJSON(schema, []byte(tt.json))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ValidateJSON() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
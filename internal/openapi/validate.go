@@ -0,0 +1,159 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidateResponse checks that a response matches what the document
+// declares for the operation and status code
+func (d *Document) ValidateResponse(method, path string, status int, contentType string, body []byte) error {
+	op := d.Operation(method, path)
+	if op == nil {
+		return fmt.Errorf("%s %s is not documented", method, path)
+	}
+
+	response, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		if response, ok = op.Responses["default"]; !ok {
+			return fmt.Errorf("%s %s does not document status %d", method, path, status)
+		}
+	}
+
+	if len(response.Content) == 0 {
+		if len(body) > 0 {
+			return fmt.Errorf("%s %s documents no body for status %d", method, path, status)
+		}
+		return nil
+	}
+
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	media, ok := response.Content[strings.TrimSpace(mediaType)]
+	if !ok {
+		return fmt.Errorf("%s %s does not document %s responses with status %d", method, path, contentType, status)
+	}
+	if media.Schema.Type == "string" {
+		return nil // Not JSON
+	}
+
+	return d.ValidateJSON(media.Schema, body)
+}
+
+// ValidateJSON checks a JSON document against a schema
+func (d *Document) ValidateJSON(s *Schema, data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return d.Validate(s, value, "$")
+}
+
+// Validate checks a value decoded from JSON with UseNumber against a
+// schema. Path names the value in errors.
+func (d *Document) Validate(s *Schema, value any, path string) error {
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		resolved, ok := d.Components.Schemas[name]
+		if !ok {
+			return fmt.Errorf("%s: unknown schema %s", path, s.Ref)
+		}
+		s = resolved
+	}
+
+	if value == nil {
+		if s.Nullable {
+			return nil
+		}
+		return fmt.Errorf("%s: null is not allowed", path)
+	}
+
+	switch s.Type {
+	case "object":
+		return d.validateObject(s, value, path)
+
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s: expected array, got %T", path, value)
+		}
+		for i, item := range items {
+			if err := d.Validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+
+	case "integer", "number":
+		n, ok := value.(json.Number)
+		if !ok {
+			return fmt.Errorf("%s: expected %s, got %T", path, s.Type, value)
+		}
+		if _, err := n.Int64(); s.Type == "integer" && err != nil {
+			return fmt.Errorf("%s: expected integer, got %s", path, n)
+		}
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected string, got %T", path, value)
+		}
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+			return fmt.Errorf("%s: %q is not one of %v", path, str, s.Enum)
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				return fmt.Errorf("%s: invalid date-time %q", path, str)
+			}
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean, got %T", path, value)
+		}
+	}
+
+	return nil
+}
+
+// validateObject checks an object's properties, rejecting unknown ones
+func (d *Document) validateObject(s *Schema, value any, path string) error {
+	object, ok := value.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%s: expected object, got %T", path, value)
+	}
+
+	for _, name := range s.Required {
+		if _, ok := object[name]; !ok {
+			return fmt.Errorf("%s: missing required property %q", path, name)
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop, ok := s.Properties[name]
+		if !ok {
+			prop = s.AdditionalProperties
+		}
+		if prop == nil {
+			return fmt.Errorf("%s: undocumented property %q", path, name)
+		}
+		if err := d.Validate(prop, object[name], path+"."+name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,270 @@
+// Package router mounts API routes under versioned path prefixes.
+//
+// Each version registers its routes once. Other prefixes can alias a
+// version, serving the same handlers with deprecation headers, so old and
+// new API versions coexist while clients migrate.
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Route is a registered method and path
+type Route struct {
+	Method     string
+	Path       string // Full path pattern, e.g. /api/v1/orders/{id}
+	Deprecated bool   // Served through a deprecated alias
+}
+
+// Deprecation describes a deprecated alias of a version
+type Deprecation struct {
+	Since  time.Time // Sent in the Deprecation header; zero sends "true"
+	Sunset time.Time // Optional date after which the alias may be removed
+}
+
+// Option configures a Router
+type Option func(*Router)
+
+// WithMethodNotAllowed sets the handler for requests with a method a path
+// does not support. The Allow header is set before it is called.
+func WithMethodNotAllowed(h http.HandlerFunc) Option {
+	return func(r *Router) {
+		r.methodNotAllowed = h
+	}
+}
+
+// WithNotFound sets the handler for paths under a version or alias prefix
+// that match no route
+func WithNotFound(h http.HandlerFunc) Option {
+	return func(r *Router) {
+		r.notFound = h
+	}
+}
+
+// WithCORS answers preflight requests and adds CORS headers allowing the
+// given origin and request headers
+func WithCORS(origin string, headers ...string) Option {
+	return func(r *Router) {
+		r.corsOrigin = origin
+		r.corsHeaders = strings.Join(headers, ", ")
+	}
+}
+
+// WithMiddleware wraps every request the router serves, including those
+// registered outside a version, e.g. for logging. Middleware registered
+// first runs outermost.
+func WithMiddleware(m func(http.Handler) http.Handler) Option {
+	return func(r *Router) {
+		r.middleware = append(r.middleware, m)
+	}
+}
+
+// Router dispatches requests to versioned routes
+type Router struct {
+	mux              *http.ServeMux
+	handler          http.Handler // mux wrapped in middleware
+	versions         []*Version
+	methodNotAllowed http.HandlerFunc
+	notFound         http.HandlerFunc
+	corsOrigin       string
+	corsHeaders      string
+	middleware       []func(http.Handler) http.Handler
+}
+
+// New creates a router
+func New(opts ...Option) *Router {
+	r := &Router{
+		mux: http.NewServeMux(),
+		methodNotAllowed: func(w http.ResponseWriter, req *http.Request) {
+			sendProblem(w, http.StatusMethodNotAllowed)
+		},
+		notFound: func(w http.ResponseWriter, req *http.Request) {
+			sendProblem(w, http.StatusNotFound)
+		},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	r.handler = r.mux
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		r.handler = r.middleware[i](r.handler)
+	}
+	return r
+}
+
+// Version returns the version mounted at prefix, e.g. "/api/v1"
+func (r *Router) Version(prefix string) *Version {
+	for _, v := range r.versions {
+		if v.prefix == prefix {
+			return v
+		}
+	}
+
+	v := &Version{router: r, prefix: prefix, endpoints: make(map[string]*endpoint)}
+	r.versions = append(r.versions, v)
+	r.mux.HandleFunc(prefix+"/", r.notFound)
+	return v
+}
+
+// Alias serves every route of the version at target under prefix as well,
+// with headers announcing the deprecation and the successor path
+func (r *Router) Alias(prefix, target string, deprecation Deprecation) {
+	v := r.Version(target)
+	a := alias{prefix: prefix, deprecation: deprecation}
+	v.aliases = append(v.aliases, a)
+	r.mux.HandleFunc(prefix+"/", r.notFound)
+
+	for _, path := range v.paths {
+		v.mount(a, path)
+	}
+}
+
+// Handle registers a handler outside any version, such as static files
+func (r *Router) Handle(pattern string, h http.Handler) {
+	r.mux.Handle(pattern, h)
+}
+
+// Routes returns every registered API route, sorted by path and method
+func (r *Router) Routes() []Route {
+	routes := []Route{}
+	for _, v := range r.versions {
+		for _, path := range v.paths {
+			for _, method := range v.endpoints[path].methods() {
+				routes = append(routes, Route{Method: method, Path: v.prefix + path})
+				for _, a := range v.aliases {
+					routes = append(routes, Route{Method: method, Path: a.prefix + path, Deprecated: true})
+				}
+			}
+		}
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// ServeHTTP dispatches a request
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.handler.ServeHTTP(w, req)
+}
+
+// sendProblem sends a bare problem response (RFC 7807) for a status
+func sendProblem(w http.ResponseWriter, status int) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"type":   "about:blank",
+		"title":  http.StatusText(status),
+		"status": status,
+	})
+}
+
+// Version is a set of routes under one path prefix
+type Version struct {
+	router    *Router
+	prefix    string
+	paths     []string // In registration order
+	endpoints map[string]*endpoint
+	aliases   []alias
+}
+
+// Handle registers a handler for a method and a path relative to the
+// version prefix, e.g. ("GET", "/orders/{id}")
+func (v *Version) Handle(method, path string, h http.HandlerFunc) {
+	e, ok := v.endpoints[path]
+	if !ok {
+		e = &endpoint{router: v.router, handlers: make(map[string]http.HandlerFunc)}
+		v.endpoints[path] = e
+		v.paths = append(v.paths, path)
+
+		v.router.mux.Handle(v.prefix+path, e)
+		for _, a := range v.aliases {
+			v.mount(a, path)
+		}
+	}
+
+	e.handlers[method] = h
+}
+
+// Prefix returns the path prefix of the version
+func (v *Version) Prefix() string {
+	return v.prefix
+}
+
+// mount serves a path of the version under an alias prefix
+func (v *Version) mount(a alias, path string) {
+	e := v.endpoints[path]
+	v.router.mux.HandleFunc(a.prefix+path, func(w http.ResponseWriter, req *http.Request) {
+		a.setHeaders(w, v.prefix+strings.TrimPrefix(req.URL.Path, a.prefix))
+		e.ServeHTTP(w, req)
+	})
+}
+
+// alias is a deprecated prefix serving a version's routes
+type alias struct {
+	prefix      string
+	deprecation Deprecation
+}
+
+// setHeaders announces the deprecation of an alias (RFC 9745, RFC 8594)
+func (a alias) setHeaders(w http.ResponseWriter, successor string) {
+	if a.deprecation.Since.IsZero() {
+		w.Header().Set("Deprecation", "true")
+	} else {
+		w.Header().Set("Deprecation", "@"+strconv.FormatInt(a.deprecation.Since.Unix(), 10))
+	}
+	if !a.deprecation.Sunset.IsZero() {
+		w.Header().Set("Sunset", a.deprecation.Sunset.UTC().Format(http.TimeFormat))
+	}
+	w.Header().Add("Link", "<"+successor+`>; rel="successor-version"`)
+}
+
+// endpoint dispatches the requests for one path by method
+type endpoint struct {
+	router   *Router
+	handlers map[string]http.HandlerFunc
+}
+
+// methods returns the registered methods, sorted
+func (e *endpoint) methods() []string {
+	methods := make([]string, 0, len(e.handlers))
+	for method := range e.handlers {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+func (e *endpoint) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	allow := strings.Join(append(e.methods(), http.MethodOptions), ", ")
+
+	if e.router.corsOrigin != "" {
+		w.Header().Set("Access-Control-Allow-Origin", e.router.corsOrigin)
+		w.Header().Set("Access-Control-Allow-Methods", allow)
+		w.Header().Set("Access-Control-Allow-Headers", e.router.corsHeaders)
+	}
+	if req.Method == http.MethodOptions {
+		w.Header().Set("Allow", allow)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	h, ok := e.handlers[req.Method]
+	if !ok {
+		w.Header().Set("Allow", allow)
+		e.router.methodNotAllowed(w, req)
+		return
+	}
+
+	h(w, req)
+}
@@ -0,0 +1,183 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestRouter serves /v1/items and /v1/items/{id} with /legacy as an alias
+func newTestRouter(deprecation Deprecation) *Router {
+	r := New(WithCORS("*", "Content-Type"))
+	v1 := r.Version("/v1")
+	v1.Handle(http.MethodGet, "/items", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("list"))
+	})
+	v1.Handle(http.MethodPost, "/items", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("create"))
+	})
+	r.Alias("/legacy", "/v1", deprecation)
+
+	// Routes registered after the alias are aliased as well
+	v1.Handle(http.MethodGet, "/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("item " + r.PathValue("id")))
+	})
+	return r
+}
+
+func TestRouterDispatch(t *testing.T) {
+	r := newTestRouter(Deprecation{})
+
+	tests := []struct {
+		method          string
+		path            string
+		wantStatus      int
+		wantBody        string
+		wantDeprecation string
+	}{
+		{http.MethodGet, "/v1/items", http.StatusOK, "list", ""},
+		{http.MethodPost, "/v1/items", http.StatusOK, "create", ""},
+		{http.MethodGet, "/v1/items/42", http.StatusOK, "item 42", ""},
+		{http.MethodGet, "/legacy/items", http.StatusOK, "list", "true"},
+		{http.MethodGet, "/legacy/items/42", http.StatusOK, "item 42", "true"},
+		{http.MethodDelete, "/v1/items", http.StatusMethodNotAllowed,
+			`{"status":405,"title":"Method Not Allowed","type":"about:blank"}` + "\n", ""},
+		{http.MethodGet, "/v1/missing", http.StatusNotFound,
+			`{"status":404,"title":"Not Found","type":"about:blank"}` + "\n", ""},
+		{http.MethodGet, "/legacy/items/42/missing", http.StatusNotFound,
+			`{"status":404,"title":"Not Found","type":"about:blank"}` + "\n", ""},
+		{http.MethodGet, "/v2/items", http.StatusNotFound, "404 page not found\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if w.Body.String() != tt.wantBody {
+				t.Errorf("Expected body %q, got %q", tt.wantBody, w.Body.String())
+			}
+			if got := w.Header().Get("Deprecation"); got != tt.wantDeprecation {
+				t.Errorf("Expected Deprecation %q, got %q", tt.wantDeprecation, got)
+			}
+		})
+	}
+}
+
+func TestRouterDeprecationHeaders(t *testing.T) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := newTestRouter(Deprecation{Since: since, Sunset: sunset})
+
+	req := httptest.NewRequest(http.MethodGet, "/legacy/items/42", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Deprecation"); got != "@1767225600" {
+		t.Errorf("Expected Deprecation @1767225600, got %q", got)
+	}
+	if got := w.Header().Get("Sunset"); got != "Fri, 01 Jan 2027 00:00:00 GMT" {
+		t.Errorf("Unexpected Sunset: %q", got)
+	}
+	if got := w.Header().Get("Link"); got != `</v1/items/42>; rel="successor-version"` {
+		t.Errorf("Unexpected Link: %q", got)
+	}
+}
+
+func TestRouterMethodsAndCORS(t *testing.T) {
+	r := newTestRouter(Deprecation{})
+
+	req := httptest.NewRequest(http.MethodOptions, "/v1/items", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST, OPTIONS" {
+		t.Errorf("Unexpected Access-Control-Allow-Methods: %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Unexpected Access-Control-Allow-Origin: %q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/v1/items/1", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Allow"); got != "GET, OPTIONS" {
+		t.Errorf("Unexpected Allow: %q", got)
+	}
+}
+
+func TestRouterNotFound(t *testing.T) {
+	r := New(WithNotFound(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	r.Version("/v1").Handle(http.MethodGet, "/items", func(w http.ResponseWriter, r *http.Request) {})
+	r.Alias("/legacy", "/v1", Deprecation{})
+
+	for _, path := range []string{"/v1/missing", "/legacy/missing"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusTeapot {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusTeapot, path, w.Code)
+		}
+	}
+}
+
+func TestRouterRoutes(t *testing.T) {
+	r := newTestRouter(Deprecation{})
+
+	want := []Route{
+		{Method: http.MethodGet, Path: "/legacy/items", Deprecated: true},
+		{Method: http.MethodPost, Path: "/legacy/items", Deprecated: true},
+		{Method: http.MethodGet, Path: "/legacy/items/{id}", Deprecated: true},
+		{Method: http.MethodGet, Path: "/v1/items"},
+		{Method: http.MethodPost, Path: "/v1/items"},
+		{Method: http.MethodGet, Path: "/v1/items/{id}"},
+	}
+	if got := r.Routes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Routes() = %v, want %v", got, want)
+	}
+}
+
+func TestRouterMiddleware(t *testing.T) {
+	var order []string
+	middleware := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, req)
+			})
+		}
+	}
+
+	r := New(WithMiddleware(middleware("outer")), WithMiddleware(middleware("inner")))
+	r.Version("/v1").Handle(http.MethodGet, "/items", func(w http.ResponseWriter, req *http.Request) {
+		order = append(order, "handler")
+	})
+	r.Handle("/static/", http.NotFoundHandler())
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/items", nil))
+	if strings.Join(order, ",") != "outer,inner,handler" {
+		t.Errorf("Unexpected order: %v", order)
+	}
+
+	// Requests outside a version go through the middleware too
+	order = nil
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/static/app.js", nil))
+	if strings.Join(order, ",") != "outer,inner" {
+		t.Errorf("Unexpected order: %v", order)
+	}
+}
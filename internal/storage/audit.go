@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"encoding/json"
+	"order-pack-calculator/internal/model"
+	"sync"
+	"time"
+)
+
+// AuditLog records administrative changes in an append-only JSON Lines file
+// Like the order history it may be shared by several instances, each of
+// which sees the changes made through the others.
+type AuditLog struct {
+	file    jsonLines
+	mu      sync.Mutex
+	records []model.AuditRecord // The most recent keepRecords, in the order they were appended
+}
+
+// AuditFilter selects audit records
+// Zero values mean no restriction; Limit 0 returns every match.
+type AuditFilter struct {
+	From   time.Time // Inclusive
+	To     time.Time // Exclusive
+	Actor  string
+	Action string
+	Limit  int
+	Offset int
+}
+
+// NewAuditLog opens the audit log stored in filename
+// An empty filename keeps the log in memory only.
+func NewAuditLog(filename string) (*AuditLog, error) {
+	l := &AuditLog{
+		file: jsonLines{filename: filename, name: "audit log"},
+	}
+
+	if err := l.file.refresh(l.load); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Record appends a change, assigning its ID and timestamp
+// Returns the stored record
+func (l *AuditLog) Record(rec model.AuditRecord) (model.AuditRecord, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	id, err := newID()
+	if err != nil {
+		return model.AuditRecord{}, err
+	}
+	rec.ID = id
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = time.Now().UTC()
+	}
+
+	if l.file.filename != "" {
+		if err := l.file.append(rec, l.load); err != nil {
+			return model.AuditRecord{}, err
+		}
+	}
+	l.add(rec)
+
+	return rec, nil
+}
+
+// Sync flushes recorded changes to disk
+func (l *AuditLog) Sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.file.sync()
+}
+
+// List returns the records matching filter, newest first, together with
+// the total number of matches before pagination
+func (l *AuditLog) List(filter AuditFilter) ([]model.AuditRecord, int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.file.refresh(l.load); err != nil {
+		return nil, 0, err
+	}
+
+	matches := []model.AuditRecord{}
+	total := 0
+	for i := len(l.records) - 1; i >= 0; i-- {
+		rec := l.records[i]
+		if !filter.From.IsZero() && rec.CreatedAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !rec.CreatedAt.Before(filter.To) {
+			continue
+		}
+		if filter.Actor != "" && rec.Actor != filter.Actor {
+			continue
+		}
+		if filter.Action != "" && rec.Action != filter.Action {
+			continue
+		}
+		total++
+		if total > filter.Offset && (filter.Limit <= 0 || len(matches) < filter.Limit) {
+			matches = append(matches, rec)
+		}
+	}
+
+	return matches, total, nil
+}
+
+// SkippedLines returns the number of lines of the file that could not be
+// parsed and were skipped
+func (l *AuditLog) SkippedLines() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.file.skipped
+}
+
+// load parses a line of the file
+func (l *AuditLog) load(line []byte) error {
+	var rec model.AuditRecord
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return err
+	}
+	l.add(rec)
+	return nil
+}
+
+// add appends a record, dropping the oldest beyond keepRecords
+func (l *AuditLog) add(rec model.AuditRecord) {
+	l.records = append(l.records, rec)
+	if len(l.records) > keepRecords {
+		l.records = l.records[1:]
+	}
+}
@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"order-pack-calculator/internal/model"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAuditLog(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	log, err := NewAuditLog(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	changes := []model.AuditRecord{
+		{Action: "pack_sizes.updated", Actor: "erp"},
+		{Action: "webhook.created", Actor: "ops"},
+		{Action: "pack_sizes.updated", Actor: "ops"},
+		{Action: "pack_sizes.updated", Actor: "erp"},
+	}
+	for i, change := range changes {
+		change.CreatedAt = start.Add(time.Duration(i) * time.Hour)
+		change.NewValue = map[string]any{"step": i}
+		rec, err := log.Record(change)
+		if err != nil {
+			t.Fatalf("Failed to record change: %v", err)
+		}
+		if rec.ID == "" {
+			t.Fatal("Recorded change should have an ID")
+		}
+	}
+
+	tests := []struct {
+		name      string
+		filter    AuditFilter
+		wantTotal int
+		wantFirst int // Step of the first record returned
+	}{
+		{"all", AuditFilter{}, 4, 3},
+		{"actor", AuditFilter{Actor: "ops"}, 2, 2},
+		{"action", AuditFilter{Action: "pack_sizes.updated"}, 3, 3},
+		{"time range", AuditFilter{From: start.Add(time.Hour), To: start.Add(3 * time.Hour)}, 2, 2},
+		{"page", AuditFilter{Limit: 1, Offset: 2}, 4, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, total, err := log.List(tt.filter)
+			if err != nil {
+				t.Fatalf("Failed to list audit log: %v", err)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("Expected %d matching records, got %d", tt.wantTotal, total)
+			}
+			if len(records) == 0 {
+				t.Fatal("Expected records")
+			}
+			if step := records[0].NewValue.(map[string]any)["step"]; step != tt.wantFirst {
+				t.Errorf("Expected step %d first, got %v", tt.wantFirst, step)
+			}
+		})
+	}
+
+	// Records survive reopening the file and other instances see them
+	reopened, err := NewAuditLog(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to reopen audit log: %v", err)
+	}
+	if _, err := log.Record(model.AuditRecord{Action: "webhook.deleted", Actor: "erp"}); err != nil {
+		t.Fatalf("Failed to record change: %v", err)
+	}
+	records, total, _ := reopened.List(AuditFilter{})
+	if total != 5 || records[0].Action != "webhook.deleted" {
+		t.Errorf("Expected 5 records with the newest first after reopening, got %d: %+v", total, records)
+	}
+}
+
+func TestAuditLogKeepsRecentRecords(t *testing.T) {
+	defer func(n int) { keepRecords = n }(keepRecords)
+	keepRecords = 3
+
+	tmpFile := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := NewAuditLog(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+
+	for _, actor := range []string{"a", "b", "c", "d", "e"} {
+		if _, err := log.Record(model.AuditRecord{Action: "pack_sizes.updated", Actor: actor}); err != nil {
+			t.Fatalf("Failed to record change: %v", err)
+		}
+	}
+
+	// The file keeps everything, but only the most recent are loaded
+	reopened, err := NewAuditLog(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to reopen audit log: %v", err)
+	}
+
+	for _, l := range []*AuditLog{log, reopened} {
+		records, total, err := l.List(AuditFilter{})
+		if err != nil {
+			t.Fatalf("Failed to list audit log: %v", err)
+		}
+		if total != 3 || len(records) != 3 {
+			t.Fatalf("Expected the 3 most recent records, got %d of %d", len(records), total)
+		}
+		if records[0].Actor != "e" || records[2].Actor != "c" {
+			t.Errorf("Expected records e to c, got %+v", records)
+		}
+	}
+}
+
+func TestAuditLogSkipsInvalidLines(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	log, err := NewAuditLog(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	if _, err := log.Record(model.AuditRecord{Action: "pack_sizes.updated"}); err != nil {
+		t.Fatalf("Failed to record change: %v", err)
+	}
+
+	// Another instance crashed halfway through writing a record
+	f, _ := os.OpenFile(tmpFile, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"id":"partial","action":"webh`)
+	f.Close()
+
+	if _, err := log.Record(model.AuditRecord{Action: "webhook.created"}); err != nil {
+		t.Fatalf("Failed to record change after a partial line: %v", err)
+	}
+	if _, err := log.Record(model.AuditRecord{Action: "webhook.deleted"}); err != nil {
+		t.Fatalf("Failed to record change: %v", err)
+	}
+
+	records, total, err := log.List(AuditFilter{})
+	if err != nil {
+		t.Fatalf("Failed to list changes: %v", err)
+	}
+	if total != 3 || records[0].Action != "webhook.deleted" {
+		t.Errorf("Expected 3 changes, newest first, got %d: %+v", total, records)
+	}
+	if got := log.SkippedLines(); got != 1 {
+		t.Errorf("Expected 1 skipped line, got %d", got)
+	}
+
+	// The file can still be opened, with the same records
+	reopened, err := NewAuditLog(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to reopen audit log: %v", err)
+	}
+	if _, total, _ := reopened.List(AuditFilter{}); total != 3 {
+		t.Errorf("Expected 3 changes after reopening, got %d", total)
+	}
+	if got := reopened.SkippedLines(); got != 1 {
+		t.Errorf("Expected 1 skipped line after reopening, got %d", got)
+	}
+}
@@ -0,0 +1,195 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"order-pack-calculator/internal/model"
+	"sort"
+	"sync"
+	"time"
+)
+
+// keepRecords is how many of the most recent records the order history and
+// the audit log keep in memory and serve; older ones stay in the file only
+var keepRecords = 100000
+
+// OrderHistory records calculation results in an append-only JSON Lines file
+// Records appended by other instances sharing the file are picked up on the
+// next read, so every replica can serve the recent history of all of them.
+type OrderHistory struct {
+	file    jsonLines
+	mu      sync.Mutex
+	records []model.OrderRecord // The most recent keepRecords, oldest first
+	byID    map[string]int      // record ID -> index in records plus dropped
+	dropped int                 // Records dropped from the front of records
+}
+
+// OrderFilter selects recorded orders
+// Zero values mean no restriction; Limit 0 returns every match.
+type OrderFilter struct {
+	From           time.Time // Inclusive
+	To             time.Time // Exclusive
+	SKU            string
+	PackSetVersion *int64
+	Limit          int
+	Offset         int
+}
+
+// NewOrderHistory opens the order history stored in filename
+// An empty filename keeps the history in memory only.
+func NewOrderHistory(filename string) (*OrderHistory, error) {
+	h := &OrderHistory{
+		file: jsonLines{filename: filename, name: "order history"},
+		byID: make(map[string]int),
+	}
+
+	if err := h.file.refresh(h.load); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// Record stores a calculation result, assigning its ID and timestamp
+// Returns the stored record
+func (h *OrderHistory) Record(rec model.OrderRecord) (model.OrderRecord, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	id, err := newID()
+	if err != nil {
+		return model.OrderRecord{}, err
+	}
+	rec.ID = id
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = time.Now().UTC()
+	}
+
+	if h.file.filename != "" {
+		if err := h.file.append(rec, h.load); err != nil {
+			return model.OrderRecord{}, err
+		}
+	}
+	h.add(rec)
+
+	return rec, nil
+}
+
+// Sync flushes recorded orders to disk
+func (h *OrderHistory) Sync() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.file.sync()
+}
+
+// Get returns the record with the given ID
+func (h *OrderHistory) Get(id string) (model.OrderRecord, bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.file.refresh(h.load); err != nil {
+		return model.OrderRecord{}, false, err
+	}
+
+	i, ok := h.byID[id]
+	if !ok {
+		return model.OrderRecord{}, false, nil
+	}
+
+	return h.records[i-h.dropped], true, nil
+}
+
+// List returns the records matching filter, newest first, together with
+// the total number of matches before pagination
+func (h *OrderHistory) List(filter OrderFilter) ([]model.OrderRecord, int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.file.refresh(h.load); err != nil {
+		return nil, 0, err
+	}
+
+	matches := []model.OrderRecord{}
+	total := 0
+	for i := len(h.records) - 1; i >= 0; i-- {
+		rec := h.records[i]
+		if !filter.From.IsZero() && rec.CreatedAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !rec.CreatedAt.Before(filter.To) {
+			continue
+		}
+		if filter.SKU != "" && rec.SKU != filter.SKU {
+			continue
+		}
+		if filter.PackSetVersion != nil && rec.PackSetVersion != *filter.PackSetVersion {
+			continue
+		}
+		total++
+		if total > filter.Offset && (filter.Limit <= 0 || len(matches) < filter.Limit) {
+			matches = append(matches, rec)
+		}
+	}
+
+	return matches, total, nil
+}
+
+// add indexes a record, keeping records ordered by creation time and
+// dropping the oldest beyond keepRecords
+func (h *OrderHistory) add(rec model.OrderRecord) {
+	if _, exists := h.byID[rec.ID]; exists {
+		return
+	}
+
+	h.records = append(h.records, rec)
+
+	// Records from other instances may interleave slightly out of order
+	n := len(h.records)
+	if n > 1 && rec.CreatedAt.Before(h.records[n-2].CreatedAt) {
+		sort.SliceStable(h.records, func(i, j int) bool {
+			return h.records[i].CreatedAt.Before(h.records[j].CreatedAt)
+		})
+		for i, r := range h.records {
+			h.byID[r.ID] = h.dropped + i
+		}
+	} else {
+		h.byID[rec.ID] = h.dropped + n - 1
+	}
+
+	if len(h.records) > keepRecords {
+		delete(h.byID, h.records[0].ID)
+		h.records = h.records[1:]
+		h.dropped++
+	}
+}
+
+// SkippedLines returns the number of lines of the file that could not be
+// parsed and were skipped
+func (h *OrderHistory) SkippedLines() int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.file.skipped
+}
+
+// load parses and indexes a line of the file
+func (h *OrderHistory) load(line []byte) error {
+	var rec model.OrderRecord
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return err
+	}
+	h.add(rec)
+	return nil
+}
+
+// newID returns a random identifier that is unique across instances
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"order-pack-calculator/internal/model"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOrderHistory(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "orders.jsonl")
+
+	history, err := NewOrderHistory(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to open order history: %v", err)
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var ids []string
+	for i := 0; i < 5; i++ {
+		rec, err := history.Record(model.OrderRecord{
+			OrderQuantity: 100 * (i + 1),
+			CreatedAt:     start.Add(time.Duration(i) * time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("Failed to record order: %v", err)
+		}
+		if rec.ID == "" {
+			t.Fatal("Recorded order should have an ID")
+		}
+		ids = append(ids, rec.ID)
+	}
+
+	// Get by ID
+	rec, found, err := history.Get(ids[2])
+	if err != nil || !found {
+		t.Fatalf("Expected to find order %s, found=%v err=%v", ids[2], found, err)
+	}
+	if rec.OrderQuantity != 300 {
+		t.Errorf("Expected order quantity 300, got %d", rec.OrderQuantity)
+	}
+
+	if _, found, _ := history.Get("missing"); found {
+		t.Error("Unknown ID should not be found")
+	}
+
+	// Time range and pagination, newest first
+	orders, total, err := history.List(OrderFilter{
+		From:  start.Add(time.Hour),
+		To:    start.Add(4 * time.Hour),
+		Limit: 2,
+	})
+	if err != nil {
+		t.Fatalf("Failed to list orders: %v", err)
+	}
+	if total != 3 {
+		t.Errorf("Expected 3 matching orders, got %d", total)
+	}
+	if len(orders) != 2 || orders[0].OrderQuantity != 400 || orders[1].OrderQuantity != 300 {
+		t.Errorf("Unexpected first page: %+v", orders)
+	}
+
+	orders, _, _ = history.List(OrderFilter{From: start.Add(time.Hour), To: start.Add(4 * time.Hour), Limit: 2, Offset: 2})
+	if len(orders) != 1 || orders[0].OrderQuantity != 200 {
+		t.Errorf("Unexpected second page: %+v", orders)
+	}
+
+	// Records survive reopening the file
+	reopened, err := NewOrderHistory(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to reopen order history: %v", err)
+	}
+	if _, total, _ := reopened.List(OrderFilter{}); total != 5 {
+		t.Errorf("Expected 5 orders after reopening, got %d", total)
+	}
+}
+
+func TestOrderHistoryKeepsRecentRecords(t *testing.T) {
+	defer func(n int) { keepRecords = n }(keepRecords)
+	keepRecords = 3
+
+	tmpFile := filepath.Join(t.TempDir(), "orders.jsonl")
+	history, err := NewOrderHistory(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to open order history: %v", err)
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var ids []string
+	for _, hour := range []int{0, 1, 2, 4, 3} { // The last one arrives out of order
+		rec, err := history.Record(model.OrderRecord{
+			OrderQuantity: 100 * (hour + 1),
+			CreatedAt:     start.Add(time.Duration(hour) * time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("Failed to record order: %v", err)
+		}
+		ids = append(ids, rec.ID)
+	}
+
+	orders, total, err := history.List(OrderFilter{})
+	if err != nil {
+		t.Fatalf("Failed to list orders: %v", err)
+	}
+	if total != 3 {
+		t.Errorf("Expected 3 orders in memory, got %d", total)
+	}
+	if len(orders) != 3 || orders[0].OrderQuantity != 500 || orders[1].OrderQuantity != 400 || orders[2].OrderQuantity != 300 {
+		t.Errorf("Expected the 3 most recent orders, got %+v", orders)
+	}
+
+	for i, id := range ids {
+		rec, found, err := history.Get(id)
+		if err != nil {
+			t.Fatalf("Failed to get order: %v", err)
+		}
+		if wantFound := i >= 2; found != wantFound {
+			t.Errorf("Expected found=%v for order %d, got %v", wantFound, i, found)
+		}
+		if found && rec.ID != id {
+			t.Errorf("Expected order %s, got %s", id, rec.ID)
+		}
+	}
+
+	// The file keeps everything, but only the most recent are loaded
+	reopened, err := NewOrderHistory(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to reopen order history: %v", err)
+	}
+	if _, total, _ := reopened.List(OrderFilter{}); total != 3 {
+		t.Errorf("Expected 3 orders after reopening, got %d", total)
+	}
+}
+
+func TestOrderHistorySharedFile(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "orders.jsonl")
+
+	first, err := NewOrderHistory(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to open order history: %v", err)
+	}
+	second, err := NewOrderHistory(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to open order history: %v", err)
+	}
+
+	rec, err := first.Record(model.OrderRecord{OrderQuantity: 251})
+	if err != nil {
+		t.Fatalf("Failed to record order: %v", err)
+	}
+	if _, err := second.Record(model.OrderRecord{OrderQuantity: 501}); err != nil {
+		t.Fatalf("Failed to record order: %v", err)
+	}
+
+	// Each instance sees the other's records
+	if _, found, _ := second.Get(rec.ID); !found {
+		t.Error("Second instance should see the first instance's record")
+	}
+	if _, total, _ := first.List(OrderFilter{}); total != 2 {
+		t.Errorf("Expected 2 orders, got %d", total)
+	}
+}
+
+func TestOrderHistoryInMemory(t *testing.T) {
+	history, err := NewOrderHistory("")
+	if err != nil {
+		t.Fatalf("Failed to create order history: %v", err)
+	}
+
+	rec, err := history.Record(model.OrderRecord{OrderQuantity: 1})
+	if err != nil {
+		t.Fatalf("Failed to record order: %v", err)
+	}
+	if _, found, _ := history.Get(rec.ID); !found {
+		t.Error("Expected to find recorded order")
+	}
+}
+
+func TestOrderHistorySkipsInvalidLines(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "orders.jsonl")
+
+	// A corrupt line and a record half-written by an instance that crashed
+	os.WriteFile(tmpFile, []byte("not json\n{\"id\":\"partial\",\"order_qu"), 0644)
+
+	history, err := NewOrderHistory(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to open order history with invalid lines: %v", err)
+	}
+
+	var ids []string
+	for _, qty := range []int{251, 501} {
+		rec, err := history.Record(model.OrderRecord{OrderQuantity: qty})
+		if err != nil {
+			t.Fatalf("Failed to record order after invalid lines: %v", err)
+		}
+		ids = append(ids, rec.ID)
+	}
+
+	if _, total, err := history.List(OrderFilter{}); err != nil || total != 2 {
+		t.Errorf("Expected 2 orders, got %d (error %v)", total, err)
+	}
+	if got := history.SkippedLines(); got != 2 {
+		t.Errorf("Expected 2 skipped lines, got %d", got)
+	}
+
+	// Another instance reads the same records
+	other, err := NewOrderHistory(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to reopen order history: %v", err)
+	}
+	for _, id := range ids {
+		if _, found, err := other.Get(id); !found || err != nil {
+			t.Errorf("Expected order %s after reopening, got found=%v error=%v", id, found, err)
+		}
+	}
+}
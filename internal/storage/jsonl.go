@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// jsonLines is an append-only JSON Lines file shared between instances
+// Lines are read incrementally, so each read only parses what other
+// instances appended since the last one. Lines that cannot be parsed, e.g.
+// left by an instance that crashed while writing, are skipped.
+type jsonLines struct {
+	filename string
+	name     string // Used in error messages, e.g. "order history"
+	offset   int64  // Bytes of the file already loaded
+	skipped  int64  // Lines that could not be parsed
+}
+
+// append writes v to the end of the file under an exclusive lock, first
+// passing lines appended by other instances to load
+func (j *jsonLines) append(v any, load func([]byte) error) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s record: %w", j.name, err)
+	}
+	line = append(line, '\n')
+
+	f, err := os.OpenFile(j.filename, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", j.name, err)
+	}
+	defer f.Close()
+
+	if err := lockFile(f, true); err != nil {
+		return fmt.Errorf("failed to lock %s: %w", j.name, err)
+	}
+	defer unlockFile(f)
+
+	// Catch up with lines appended by other instances first so the offset
+	// keeps pointing at the end of what we have loaded
+	if err := j.readFrom(f, load); err != nil {
+		return err
+	}
+
+	// A writer that crashed mid-line leaves an unterminated tail; end it so
+	// the record starts on a line of its own and the tail is skipped
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", j.name, err)
+	}
+	if info.Size() > j.offset {
+		if _, err := f.Write([]byte{'\n'}); err != nil {
+			return fmt.Errorf("failed to write %s: %w", j.name, err)
+		}
+		if err := j.readFrom(f, load); err != nil {
+			return err
+		}
+	}
+
+	if _, err := f.Write(line); err != nil {
+		return fmt.Errorf("failed to write %s: %w", j.name, err)
+	}
+	j.offset += int64(len(line))
+
+	return nil
+}
+
+// sync flushes the file to disk
+func (j *jsonLines) sync() error {
+	if j.filename == "" {
+		return nil
+	}
+	return syncFile(j.filename)
+}
+
+// refresh passes lines appended to the file since the last read to load
+func (j *jsonLines) refresh(load func([]byte) error) error {
+	if j.filename == "" {
+		return nil
+	}
+
+	f, err := os.Open(j.filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", j.name, err)
+	}
+	defer f.Close()
+
+	if err := lockFile(f, false); err != nil {
+		return fmt.Errorf("failed to lock %s: %w", j.name, err)
+	}
+	defer unlockFile(f)
+
+	return j.readFrom(f, load)
+}
+
+// readFrom passes complete lines after the current offset to load
+// Lines load rejects are logged and counted, then skipped.
+func (j *jsonLines) readFrom(f *os.File, load func([]byte) error) error {
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", j.name, err)
+	}
+	if info.Size() <= j.offset {
+		return nil
+	}
+
+	data, err := io.ReadAll(io.NewSectionReader(f, j.offset, info.Size()-j.offset))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", j.name, err)
+	}
+
+	for {
+		// Leave a trailing partial line for the next read
+		end := bytes.IndexByte(data, '\n')
+		if end < 0 {
+			return nil
+		}
+
+		if line := bytes.TrimSpace(data[:end]); len(line) > 0 {
+			if err := load(line); err != nil {
+				j.skipped++
+				slog.Warn("Skipping invalid line in "+j.name, "file", j.filename, "offset", j.offset, "error", err)
+			}
+		}
+		j.offset += int64(end + 1)
+		data = data[end+1:]
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !unix
+
+package storage
+
+import "os"
+
+// lockFile is a no-op on platforms without flock
+// Only the in-process mutex protects the storage file there.
+func lockFile(f *os.File, exclusive bool) error {
+	return nil
+}
+
+// unlockFile is a no-op on platforms without flock
+func unlockFile(f *os.File) error {
+	return nil
+}
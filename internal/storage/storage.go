@@ -1,16 +1,32 @@
 package storage
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sync"
+	"time"
 )
 
 // Storage provides persistence for pack sizes configuration
+// An advisory lock on a sibling ".lock" file serialises access between
+// processes sharing the same file, e.g. replicas on a shared volume.
 type Storage struct {
 	filename string
 	mu       sync.RWMutex
+	lastData []byte // File content last loaded or saved by this instance
+}
+
+// Config is the persisted pack sizes configuration
+// Version increases on every save so instances sharing the file can tell
+// newer configurations from stale ones. Files holding a bare JSON array
+// (e.g. written by config management) have version 0.
+type Config struct {
+	Version   int64 `json:"version"`
+	PackSizes []int `json:"pack_sizes"`
 }
 
 // NewStorage creates a new storage instance
@@ -24,53 +40,93 @@ func NewStorage(filename string) *Storage {
 // LoadPackSizes loads pack sizes from file
 // Returns the pack sizes and any error encountered
 func (s *Storage) LoadPackSizes() ([]int, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	cfg, err := s.Load()
+	return cfg.PackSizes, err
+}
+
+// SavePackSizes saves pack sizes to file
+// Returns any error encountered during save
+func (s *Storage) SavePackSizes(packSizes []int) error {
+	_, err := s.Save(packSizes, 0)
+	return err
+}
+
+// Load loads the configuration from file under a shared lock
+// A missing or empty file yields a zero Config
+func (s *Storage) Load() (Config, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	// Check if file exists
+	// Nothing to load, and no reason to create a lock file yet
 	if _, err := os.Stat(s.filename); os.IsNotExist(err) {
-		// File doesn't exist, return empty slice (will use defaults)
-		return nil, nil
+		return Config{}, nil
 	}
 
-	// Read file
-	data, err := os.ReadFile(s.filename)
+	unlock, err := s.lock(false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read storage file: %w", err)
+		return Config{}, err
 	}
+	defer unlock()
 
-	// Parse JSON
-	var packSizes []int
-	if len(data) == 0 {
-		// Empty file, return nil
-		return nil, nil
+	data, err := s.read()
+	if err != nil || data == nil {
+		return Config{}, err
 	}
+	s.lastData = data
 
-	if err := json.Unmarshal(data, &packSizes); err != nil {
-		return nil, fmt.Errorf("failed to parse storage file: %w", err)
-	}
-
-	return packSizes, nil
+	return parseConfig(data)
 }
 
-// SavePackSizes saves pack sizes to file
-// Returns any error encountered during save
-func (s *Storage) SavePackSizes(packSizes []int) error {
+// Save writes pack sizes to file under an exclusive lock
+// The new version is one above both the stored version and the caller's
+// current version, which may be ahead of the file after reloading a bare
+// array. Returns the new configuration.
+func (s *Storage) Save(packSizes []int, version int64) (Config, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	unlock, err := s.lock(true)
+	if err != nil {
+		return Config{}, err
+	}
+	defer unlock()
+
+	// Read the current version, which another instance may have bumped
+	var current Config
+	data, err := s.read()
+	if err != nil {
+		return Config{}, err
+	}
+	if data != nil {
+		// An unreadable file is overwritten, starting again from version 0
+		current, _ = parseConfig(data)
+	}
+
+	cfg := Config{
+		Version:   max(current.Version, version) + 1,
+		PackSizes: packSizes,
+	}
+
 	// Marshal to JSON
-	data, err := json.MarshalIndent(packSizes, "", "  ")
+	data, err = json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal pack sizes: %w", err)
+		return Config{}, fmt.Errorf("failed to marshal pack sizes: %w", err)
 	}
 
-	// Write to file
-	if err := os.WriteFile(s.filename, data, 0644); err != nil {
-		return fmt.Errorf("failed to write storage file: %w", err)
+	if err := writeFile(s.filename, data, 0644); err != nil {
+		return Config{}, err
 	}
+	s.lastData = data
 
-	return nil
+	return cfg, nil
+}
+
+// Sync flushes the storage file to disk, e.g. before the process exits
+func (s *Storage) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return syncFile(s.filename)
 }
 
 // FileExists checks if the storage file exists
@@ -81,3 +137,193 @@ func (s *Storage) FileExists() bool {
 	_, err := os.Stat(s.filename)
 	return !os.IsNotExist(err)
 }
+
+// Check reports whether the storage file can be used: its content, if any,
+// must be a valid configuration, and its lock file and directory writable
+// so that saves succeed. The file itself is left untouched.
+func (s *Storage) Check() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, err := parseConfig(data); err != nil {
+		return err
+	}
+
+	lock, err := os.OpenFile(s.filename+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file: %w", err)
+	}
+	lock.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.filename), filepath.Base(s.filename)+".check-*")
+	if err != nil {
+		return fmt.Errorf("storage directory is not writable: %w", err)
+	}
+	tmp.Close()
+	os.Remove(tmp.Name())
+
+	return nil
+}
+
+// Watch polls the storage file every interval until ctx is cancelled
+// onChange is called whenever the file content differs from what this
+// instance last loaded or saved, with either the new configuration or the
+// error encountered while reading it. A missing file is ignored.
+func (s *Storage) Watch(ctx context.Context, interval time.Duration, onChange func(cfg Config, err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			data, changed, err := s.poll()
+			if err != nil {
+				onChange(Config{}, err)
+				continue
+			}
+			if !changed {
+				continue
+			}
+			onChange(parseConfig(data))
+		}
+	}
+}
+
+// poll reads the storage file and reports whether its content changed
+func (s *Storage) poll() ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := os.Stat(s.filename); os.IsNotExist(err) {
+		return nil, false, nil
+	}
+
+	unlock, err := s.lock(false)
+	if err != nil {
+		return nil, false, err
+	}
+	defer unlock()
+
+	data, err := s.read()
+	if err != nil || data == nil {
+		return nil, false, err
+	}
+
+	if s.lastData != nil && bytes.Equal(data, s.lastData) {
+		return nil, false, nil
+	}
+	s.lastData = data
+
+	return data, true, nil
+}
+
+// lock takes the inter-process lock and returns a function releasing it
+func (s *Storage) lock(exclusive bool) (func(), error) {
+	f, err := os.OpenFile(s.filename+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := lockFile(f, exclusive); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock storage file: %w", err)
+	}
+
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// read returns the storage file content, or nil if it doesn't exist
+func (s *Storage) read() ([]byte, error) {
+	return readFile(s.filename)
+}
+
+// readFile returns a storage file's content, or nil if it doesn't exist
+func readFile(filename string) ([]byte, error) {
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read storage file: %w", err)
+	}
+	return data, nil
+}
+
+// writeFile atomically replaces a storage file so readers never see a
+// partially written file, even those not taking the lock
+func writeFile(filename string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write storage file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write storage file: %w", err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write storage file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write storage file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return fmt.Errorf("failed to write storage file: %w", err)
+	}
+
+	return nil
+}
+
+// syncFile flushes a file to disk
+// A missing file has nothing to flush.
+func syncFile(filename string) error {
+	f, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to sync %s: %w", filename, err)
+	}
+	defer f.Close()
+
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to sync %s: %w", filename, err)
+	}
+	return nil
+}
+
+// parseConfig decodes storage file content
+// Both the versioned object and the legacy bare array are accepted.
+// Empty content yields a zero Config.
+func parseConfig(data []byte) (Config, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return Config{}, nil
+	}
+
+	var cfg Config
+	if trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &cfg.PackSizes); err != nil {
+			return Config{}, fmt.Errorf("failed to parse storage file: %w", err)
+		}
+		return cfg, nil
+	}
+
+	if err := json.Unmarshal(trimmed, &cfg); err != nil {
+		return Config{}, fmt.Errorf("failed to parse storage file: %w", err)
+	}
+
+	return cfg, nil
+}
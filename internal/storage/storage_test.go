@@ -1,14 +1,20 @@
 package storage
 
 import (
+	"context"
+	"order-pack-calculator/internal/model"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestStorage(t *testing.T) {
 	// Use temporary file for testing
 	tmpFile := "test_pack_sizes.json"
 	defer os.Remove(tmpFile) // Clean up
+	defer os.Remove(tmpFile + ".lock")
 
 	storage := NewStorage(tmpFile)
 
@@ -56,6 +62,7 @@ func TestStorageNonExistentFile(t *testing.T) {
 func TestStorageEmptyFile(t *testing.T) {
 	tmpFile := "test_empty.json"
 	defer os.Remove(tmpFile)
+	defer os.Remove(tmpFile + ".lock")
 
 	// Create empty file
 	if err := os.WriteFile(tmpFile, []byte(""), 0644); err != nil {
@@ -71,3 +78,224 @@ func TestStorageEmptyFile(t *testing.T) {
 		t.Errorf("Loading from empty file should return nil, got: %v", packSizes)
 	}
 }
+
+func TestStorageWatch(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "pack_sizes.json")
+
+	storage := NewStorage(tmpFile)
+	if err := storage.SavePackSizes([]int{250, 500}); err != nil {
+		t.Fatalf("Failed to save pack sizes: %v", err)
+	}
+
+	type change struct {
+		cfg Config
+		err error
+	}
+	changes := make(chan change, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go storage.Watch(ctx, 10*time.Millisecond, func(cfg Config, err error) {
+		changes <- change{cfg, err}
+	})
+
+	// Our own save must not be reported as a change
+	select {
+	case c := <-changes:
+		t.Fatalf("Unexpected change after own save: %+v", c)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// External edit is reported with the new content
+	if err := os.WriteFile(tmpFile, []byte("[23, 31, 53]"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	select {
+	case c := <-changes:
+		if c.err != nil || len(c.cfg.PackSizes) != 3 || c.cfg.PackSizes[0] != 23 {
+			t.Errorf("Unexpected change: %+v", c)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for change")
+	}
+
+	// Invalid content is reported as an error
+	if err := os.WriteFile(tmpFile, []byte("not json"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	select {
+	case c := <-changes:
+		if c.err == nil {
+			t.Errorf("Expected parse error, got %+v", c)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for change")
+	}
+}
+
+func TestStorageVersioning(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "pack_sizes.json")
+
+	// Two instances sharing a file, as replicas on a shared volume would
+	first := NewStorage(tmpFile)
+	second := NewStorage(tmpFile)
+
+	cfg, err := first.Save([]int{250, 500}, 0)
+	if err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	if cfg.Version != 1 {
+		t.Errorf("Expected version 1 after first save, got %d", cfg.Version)
+	}
+
+	cfg, err = second.Save([]int{100}, 0)
+	if err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	if cfg.Version != 2 {
+		t.Errorf("Expected version 2 after second save, got %d", cfg.Version)
+	}
+
+	loaded, err := first.Load()
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if loaded.Version != 2 || len(loaded.PackSizes) != 1 || loaded.PackSizes[0] != 100 {
+		t.Errorf("Expected version 2 with [100], got %+v", loaded)
+	}
+}
+
+func TestStorageConcurrentSaves(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "pack_sizes.json")
+
+	const instances = 8
+	var wg sync.WaitGroup
+	for i := 0; i < instances; i++ {
+		wg.Add(1)
+		go func(size int) {
+			defer wg.Done()
+			if _, err := NewStorage(tmpFile).Save([]int{size}, 0); err != nil {
+				t.Errorf("Failed to save: %v", err)
+			}
+		}(i + 1)
+	}
+	wg.Wait()
+
+	// Every save must have observed the previous one's version
+	cfg, err := NewStorage(tmpFile).Load()
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if cfg.Version != instances {
+		t.Errorf("Expected version %d, got %d", instances, cfg.Version)
+	}
+}
+
+func TestStorageLegacyFormat(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "pack_sizes.json")
+	if err := os.WriteFile(tmpFile, []byte("[250, 500, 1000]"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	storage := NewStorage(tmpFile)
+	cfg, err := storage.Load()
+	if err != nil {
+		t.Fatalf("Failed to load legacy file: %v", err)
+	}
+	if cfg.Version != 0 || len(cfg.PackSizes) != 3 {
+		t.Errorf("Expected version 0 with 3 pack sizes, got %+v", cfg)
+	}
+
+	// Saving upgrades the file to the versioned format
+	cfg, err = storage.Save([]int{250}, 0)
+	if err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	if cfg.Version != 1 {
+		t.Errorf("Expected version 1, got %d", cfg.Version)
+	}
+
+	// A caller ahead of the file, e.g. after reloading a bare array, never
+	// goes back
+	if err := os.WriteFile(tmpFile, []byte("[250, 500]"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	cfg, err = storage.Save([]int{500}, 4)
+	if err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	if cfg.Version != 5 {
+		t.Errorf("Expected version 5, got %d", cfg.Version)
+	}
+}
+
+func TestStorageCheck(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		filename string
+		content  string // Written first unless empty
+		wantErr  bool
+	}{
+		{"missing file", filepath.Join(dir, "new.json"), "", false},
+		{"valid file", filepath.Join(dir, "valid.json"), `{"version": 2, "pack_sizes": [250, 500]}`, false},
+		{"invalid file", filepath.Join(dir, "invalid.json"), `{"pack_sizes": "250"}`, true},
+		{"missing directory", filepath.Join(dir, "missing", "packs.json"), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.content != "" {
+				if err := os.WriteFile(tt.filename, []byte(tt.content), 0644); err != nil {
+					t.Fatalf("Failed to write file: %v", err)
+				}
+			}
+
+			err := NewStorage(tt.filename).Check()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Checking leaves nothing behind but the lock file
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) != ".json" && filepath.Ext(entry.Name()) != ".lock" && !entry.IsDir() {
+			t.Errorf("Unexpected file left behind: %s", entry.Name())
+		}
+	}
+}
+
+func TestSync(t *testing.T) {
+	dir := t.TempDir()
+
+	stor := NewStorage(filepath.Join(dir, "packs.json"))
+	if err := stor.Sync(); err != nil {
+		t.Errorf("Expected nothing to sync before the first save, got %v", err)
+	}
+	if err := stor.SavePackSizes([]int{250, 500}); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+
+	history, _ := NewOrderHistory(filepath.Join(dir, "orders.jsonl"))
+	history.Record(model.OrderRecord{OrderQuantity: 251})
+	memoryHistory, _ := NewOrderHistory("")
+	audit, _ := NewAuditLog(filepath.Join(dir, "audit.jsonl"))
+	audit.Record(model.AuditRecord{Action: "pack_sizes.updated"})
+	webhooks := NewWebhookStore(filepath.Join(dir, "webhooks.json"))
+	webhooks.Save(nil)
+
+	for name, flush := range map[string]func() error{
+		"storage":        stor.Sync,
+		"history":        history.Sync,
+		"memory history": memoryHistory.Sync,
+		"audit log":      audit.Sync,
+		"webhooks":       webhooks.Sync,
+	} {
+		if err := flush(); err != nil {
+			t.Errorf("Failed to sync %s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"order-pack-calculator/internal/model"
+	"sync"
+)
+
+// WebhookStore persists webhook subscriptions with their secrets in a JSON
+// file, which should only be readable by the server
+type WebhookStore struct {
+	filename string
+	mu       sync.Mutex
+}
+
+// StoredWebhook is a webhook subscription with its signing secret
+type StoredWebhook struct {
+	model.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// NewWebhookStore creates a store for webhook subscriptions in filename
+func NewWebhookStore(filename string) *WebhookStore {
+	return &WebhookStore{filename: filename}
+}
+
+// Load returns the stored webhook subscriptions
+// A missing or empty file holds no subscriptions.
+func (s *WebhookStore) Load() ([]StoredWebhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := readFile(s.filename)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	var webhooks []StoredWebhook
+	if err := json.Unmarshal(data, &webhooks); err != nil {
+		return nil, fmt.Errorf("failed to parse webhooks file: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// Save replaces the stored webhook subscriptions
+func (s *WebhookStore) Save(webhooks []StoredWebhook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if webhooks == nil {
+		webhooks = []StoredWebhook{}
+	}
+	data, err := json.MarshalIndent(webhooks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhooks: %w", err)
+	}
+
+	return writeFile(s.filename, data, 0600)
+}
+
+// Sync flushes the webhooks file to disk
+func (s *WebhookStore) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return syncFile(s.filename)
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"order-pack-calculator/internal/model"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWebhookStore(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "webhooks.json")
+	store := NewWebhookStore(file)
+
+	// A missing file holds no subscriptions
+	webhooks, err := store.Load()
+	if err != nil || len(webhooks) != 0 {
+		t.Fatalf("Expected no webhooks from a missing file, got %v (%v)", webhooks, err)
+	}
+
+	saved := []StoredWebhook{{
+		WebhookSubscription: model.WebhookSubscription{ID: "a1", URL: "http://example.com/hook", Events: []string{"order.calculated"}},
+		Secret:              "s3cret",
+	}}
+	if err := store.Save(saved); err != nil {
+		t.Fatalf("Failed to save webhooks: %v", err)
+	}
+
+	// Secrets are stored, so only the owner may read the file
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatalf("Failed to stat webhooks file: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Expected file mode 0600, got %o", perm)
+	}
+
+	webhooks, err = store.Load()
+	if err != nil {
+		t.Fatalf("Failed to load webhooks: %v", err)
+	}
+	if len(webhooks) != 1 || webhooks[0].ID != "a1" || webhooks[0].Secret != "s3cret" {
+		t.Errorf("Expected the saved webhook with its secret, got %+v", webhooks)
+	}
+}
@@ -0,0 +1,611 @@
+// Package webhook delivers server events to subscribed URLs
+//
+// Every delivery is a POST of a model.WebhookEvent signed with the
+// subscription's secret. Deliveries wait in a bounded queue for a fixed
+// pool of workers. Failed deliveries are queued again after an exponential
+// backoff, during which they hold no worker; those still failing after the
+// last attempt, or that do not fit in the queue, are kept as dead letters
+// until they are redelivered.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"order-pack-calculator/internal/model"
+	"order-pack-calculator/internal/storage"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Events that webhooks can subscribe to
+const (
+	EventPackSizesUpdated = "pack_sizes.updated" // Data is a model.PackSizesResponse
+	EventOrderCalculated  = "order.calculated"   // Data is a model.CalculateResponse
+)
+
+// Events lists every event webhooks can subscribe to
+var Events = []string{EventPackSizesUpdated, EventOrderCalculated}
+
+// Delivery statuses
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+// Headers sent with every delivery
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Default delivery settings
+const (
+	DefaultMaxAttempts = 5
+	DefaultBackoff     = time.Second
+	DefaultMaxBackoff  = 5 * time.Minute
+	DefaultTimeout     = 10 * time.Second
+	DefaultLogSize     = 1000
+	DefaultWorkers     = 8
+	DefaultQueueSize   = 10000
+)
+
+// Errors returned by the Dispatcher
+var (
+	// ErrNotFound is returned for an unknown webhook or dead letter
+	ErrNotFound = errors.New("webhook: not found")
+
+	// ErrWebhookRemoved is returned when redelivering a dead letter whose
+	// webhook no longer exists
+	ErrWebhookRemoved = errors.New("webhook: subscription was removed")
+)
+
+// Option configures a Dispatcher
+type Option func(*Dispatcher)
+
+// WithHTTPClient sets the client used for deliveries
+func WithHTTPClient(client *http.Client) Option {
+	return func(d *Dispatcher) {
+		d.client = client
+	}
+}
+
+// WithRetries sets how often a delivery is attempted and how long to wait
+// between attempts. The wait doubles after every attempt up to maxBackoff.
+func WithRetries(maxAttempts int, backoff, maxBackoff time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.maxAttempts = max(maxAttempts, 1)
+		d.backoff = backoff
+		d.maxBackoff = maxBackoff
+	}
+}
+
+// WithLogSize sets how many recent deliveries and dead letters are kept
+func WithLogSize(size int) Option {
+	return func(d *Dispatcher) {
+		d.logSize = max(size, 1)
+	}
+}
+
+// WithQueue sets how many deliveries are made at once and how many more
+// may wait for a worker. Deliveries beyond the queue become dead letters.
+func WithQueue(workers, size int) Option {
+	return func(d *Dispatcher) {
+		d.workers = max(workers, 1)
+		d.queueSize = max(size, 1)
+	}
+}
+
+// Dispatcher manages webhook subscriptions and delivers events to them
+// A Dispatcher is safe for concurrent use.
+type Dispatcher struct {
+	client      *http.Client
+	store       *storage.WebhookStore // Optional persistence of subscriptions
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	logSize     int
+	workers     int
+	queueSize   int
+
+	mu          sync.Mutex
+	webhooks    []storage.StoredWebhook
+	deliveries  []*model.WebhookDelivery // Oldest first
+	deadLetters []*model.WebhookDelivery // Oldest first
+	dropped     int64                    // Deliveries that did not fit in the queue
+	closing     bool                     // Set by Close; no new deliveries are queued
+	undelivered []model.WebhookDelivery  // Failed since Close was called
+
+	queue   chan job
+	pending sync.WaitGroup  // Queued deliveries and those in progress
+	ctx     context.Context // Cancelled by Close to stop retries
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup // Running workers and retries waiting to be queued
+}
+
+// job is a delivery waiting for a worker
+type job struct {
+	delivery *model.WebhookDelivery
+	secret   string
+	body     []byte        // Set by the first attempt
+	wait     time.Duration // Backoff before the next attempt
+}
+
+// New creates a dispatcher, loading subscriptions from store if it is not nil
+// Without a store subscriptions are kept in memory only.
+func New(store *storage.WebhookStore, opts ...Option) (*Dispatcher, error) {
+	d := &Dispatcher{
+		client:      &http.Client{Timeout: DefaultTimeout},
+		store:       store,
+		maxAttempts: DefaultMaxAttempts,
+		backoff:     DefaultBackoff,
+		maxBackoff:  DefaultMaxBackoff,
+		logSize:     DefaultLogSize,
+		workers:     DefaultWorkers,
+		queueSize:   DefaultQueueSize,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	if store != nil {
+		webhooks, err := store.Load()
+		if err != nil {
+			return nil, err
+		}
+		d.webhooks = webhooks
+	}
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+
+	d.queue = make(chan job, d.queueSize)
+	for range d.workers {
+		d.wg.Add(1)
+		go d.work()
+	}
+
+	return d, nil
+}
+
+// Webhooks returns the subscriptions, oldest first
+func (d *Dispatcher) Webhooks() []model.WebhookSubscription {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	webhooks := []model.WebhookSubscription{}
+	for _, w := range d.webhooks {
+		webhooks = append(webhooks, w.WebhookSubscription)
+	}
+	return webhooks
+}
+
+// Webhook returns a subscription by ID
+func (d *Dispatcher) Webhook(id string) (model.WebhookSubscription, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	i := d.find(id)
+	if i < 0 {
+		return model.WebhookSubscription{}, ErrNotFound
+	}
+	return d.webhooks[i].WebhookSubscription, nil
+}
+
+// Add subscribes a URL to events, assigning its ID
+// The caller validates the URL and events.
+func (d *Dispatcher) Add(url string, events []string, secret string) (model.WebhookSubscription, error) {
+	id, err := newID()
+	if err != nil {
+		return model.WebhookSubscription{}, err
+	}
+
+	w := storage.StoredWebhook{
+		WebhookSubscription: model.WebhookSubscription{
+			ID:        id,
+			URL:       url,
+			Events:    slices.Clone(events),
+			CreatedAt: time.Now().UTC(),
+		},
+		Secret: secret,
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	webhooks := append(slices.Clone(d.webhooks), w)
+	if err := d.save(webhooks); err != nil {
+		return model.WebhookSubscription{}, err
+	}
+	d.webhooks = webhooks
+
+	return w.WebhookSubscription, nil
+}
+
+// Remove deletes a subscription
+// Deliveries already under way are still attempted.
+func (d *Dispatcher) Remove(id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	i := d.find(id)
+	if i < 0 {
+		return ErrNotFound
+	}
+
+	webhooks := slices.Delete(slices.Clone(d.webhooks), i, i+1)
+	if err := d.save(webhooks); err != nil {
+		return err
+	}
+	d.webhooks = webhooks
+
+	return nil
+}
+
+// Publish delivers an event to every webhook subscribed to it
+// Deliveries happen in the background; Publish never blocks on them. If
+// the queue is full they become dead letters straight away.
+func (d *Dispatcher) Publish(event string, data any) {
+	if d == nil {
+		return
+	}
+
+	id, err := newID()
+	if err != nil {
+		return
+	}
+	payload := model.WebhookEvent{ID: id, Event: event, CreatedAt: time.Now().UTC(), Data: data}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closing {
+		return
+	}
+
+	for _, w := range d.webhooks {
+		if !slices.Contains(w.Events, event) {
+			continue
+		}
+
+		deliveryID, err := newID()
+		if err != nil {
+			continue
+		}
+		delivery := &model.WebhookDelivery{
+			ID:        deliveryID,
+			WebhookID: w.ID,
+			URL:       w.URL,
+			Event:     event,
+			Status:    StatusPending,
+			CreatedAt: payload.CreatedAt,
+			UpdatedAt: payload.CreatedAt,
+			Payload:   payload,
+		}
+		d.deliveries = appendLog(d.deliveries, delivery, d.logSize)
+		d.start(delivery, w.Secret)
+	}
+}
+
+// Dropped returns how many deliveries became dead letters because the queue
+// was full
+func (d *Dispatcher) Dropped() int64 {
+	if d == nil {
+		return 0
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.dropped
+}
+
+// Deliveries returns the most recent deliveries, newest first
+func (d *Dispatcher) Deliveries() []model.WebhookDelivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return newestFirst(d.deliveries)
+}
+
+// DeadLetters returns the deliveries that failed every attempt, newest first
+func (d *Dispatcher) DeadLetters() []model.WebhookDelivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return newestFirst(d.deadLetters)
+}
+
+// Redeliver takes a dead letter off the list and delivers it again with a
+// fresh set of attempts, to its webhook's current URL and secret
+func (d *Dispatcher) Redeliver(id string) (model.WebhookDelivery, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	i := slices.IndexFunc(d.deadLetters, func(dl *model.WebhookDelivery) bool { return dl.ID == id })
+	if i < 0 {
+		return model.WebhookDelivery{}, ErrNotFound
+	}
+	delivery := d.deadLetters[i]
+
+	w := d.find(delivery.WebhookID)
+	if w < 0 {
+		return model.WebhookDelivery{}, ErrWebhookRemoved
+	}
+
+	d.deadLetters = slices.Delete(d.deadLetters, i, i+1)
+	delivery.URL = d.webhooks[w].URL
+	delivery.Status = StatusPending
+	delivery.Attempts = 0
+	delivery.ResponseStatus = 0
+	delivery.Error = ""
+	delivery.UpdatedAt = time.Now().UTC()
+	if !slices.Contains(d.deliveries, delivery) {
+		d.deliveries = appendLog(d.deliveries, delivery, d.logSize)
+	}
+	d.start(delivery, d.webhooks[w].Secret)
+
+	return *delivery, nil
+}
+
+// Close stops accepting events and lets queued deliveries and those in
+// progress, including their retries, finish until ctx is done. It then
+// cancels the rest and returns the deliveries that failed meanwhile, which
+// also become dead letters.
+func (d *Dispatcher) Close(ctx context.Context) []model.WebhookDelivery {
+	if d == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	d.closing = true
+	d.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		d.pending.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+	}
+
+	d.cancel()
+	d.wg.Wait()
+
+	// Nothing is queued once closing, so the queue drains for good
+	for empty := false; !empty; {
+		select {
+		case j := <-d.queue:
+			d.finish(j.delivery, 0, errors.New("not delivered before shutdown"))
+			d.pending.Done()
+		default:
+			empty = true
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.undelivered
+}
+
+// Sign returns the signature of a delivery body sent at timestamp (Unix
+// seconds), as sent in the X-Webhook-Signature header: "sha256=" followed
+// by the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the secret
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// start queues a delivery for a worker, or turns it into a dead letter if
+// the queue is full or the dispatcher closed. Callers must hold d.mu.
+func (d *Dispatcher) start(delivery *model.WebhookDelivery, secret string) {
+	if d.closing {
+		d.fail(delivery, 0, errors.New("not delivered after shutdown"))
+		return
+	}
+
+	d.pending.Add(1)
+	select {
+	case d.queue <- job{delivery: delivery, secret: secret}:
+	default:
+		d.pending.Done()
+		d.dropped++
+		d.fail(delivery, 0, fmt.Errorf("delivery queue is full (%d waiting)", d.queueSize))
+	}
+}
+
+// work delivers queued deliveries until the dispatcher is closed
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case j := <-d.queue:
+			if d.deliver(j) {
+				d.pending.Done()
+			}
+		case <-d.ctx.Done():
+			return
+		}
+	}
+}
+
+// deliver makes one attempt at a delivery and reports whether it is
+// finished. A failed attempt with attempts left is retried after a backoff.
+func (d *Dispatcher) deliver(j job) bool {
+	d.mu.Lock()
+	url, payload, attempt := j.delivery.URL, j.delivery.Payload, j.delivery.Attempts+1
+	d.mu.Unlock()
+
+	if j.body == nil {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			d.finish(j.delivery, 0, err)
+			return true
+		}
+		j.body = body
+		j.wait = d.backoff
+	}
+
+	status, err := d.send(url, j.delivery.ID, payload.Event, j.secret, j.body)
+
+	d.mu.Lock()
+	j.delivery.Attempts = attempt
+	j.delivery.ResponseStatus = status
+	j.delivery.UpdatedAt = time.Now().UTC()
+	d.mu.Unlock()
+
+	if err == nil {
+		d.finish(j.delivery, status, nil)
+		return true
+	}
+	if attempt >= d.maxAttempts {
+		d.finish(j.delivery, status, err)
+		return true
+	}
+
+	d.mu.Lock()
+	j.delivery.Error = err.Error()
+	d.mu.Unlock()
+
+	d.wg.Add(1)
+	go d.retry(j, status, err)
+	return false
+}
+
+// retry queues a failed delivery again once its backoff has passed, so
+// that the worker is free for other deliveries meanwhile
+func (d *Dispatcher) retry(j job, status int, err error) {
+	defer d.wg.Done()
+
+	timer := time.NewTimer(j.wait)
+	defer timer.Stop()
+	j.wait = min(2*j.wait, d.maxBackoff)
+
+	select {
+	case <-timer.C:
+		select {
+		case d.queue <- j:
+			return
+		case <-d.ctx.Done():
+		}
+	case <-d.ctx.Done():
+	}
+
+	d.finish(j.delivery, status, fmt.Errorf("%w; not retried after shutdown", err))
+	d.pending.Done()
+}
+
+// send makes one delivery attempt and returns the response status
+// Any status other than 2xx is an error.
+func (d *Dispatcher) send(url, deliveryID, event, secret string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "order-pack-calculator-webhooks")
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderDelivery, deliveryID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // Allow the connection to be reused
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// finish records the outcome of a delivery, adding failures to the dead
+// letters
+func (d *Dispatcher) finish(delivery *model.WebhookDelivery, status int, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err != nil {
+		d.fail(delivery, status, err)
+		return
+	}
+	delivery.Status = StatusDelivered
+	delivery.ResponseStatus = status
+	delivery.Error = ""
+	delivery.UpdatedAt = time.Now().UTC()
+}
+
+// fail marks a delivery as failed and adds it to the dead letters
+// Callers must hold d.mu.
+func (d *Dispatcher) fail(delivery *model.WebhookDelivery, status int, err error) {
+	delivery.Status = StatusFailed
+	delivery.ResponseStatus = status
+	delivery.Error = err.Error()
+	delivery.UpdatedAt = time.Now().UTC()
+	d.deadLetters = appendLog(d.deadLetters, delivery, d.logSize)
+	if d.closing {
+		d.undelivered = append(d.undelivered, *delivery)
+	}
+}
+
+// find returns the index of a subscription, or -1. Callers must hold d.mu.
+func (d *Dispatcher) find(id string) int {
+	return slices.IndexFunc(d.webhooks, func(w storage.StoredWebhook) bool { return w.ID == id })
+}
+
+// save persists subscriptions if a store is configured
+func (d *Dispatcher) save(webhooks []storage.StoredWebhook) error {
+	if d.store == nil {
+		return nil
+	}
+	return d.store.Save(webhooks)
+}
+
+// appendLog appends a delivery, dropping the oldest beyond size
+func appendLog(log []*model.WebhookDelivery, delivery *model.WebhookDelivery, size int) []*model.WebhookDelivery {
+	log = append(log, delivery)
+	if len(log) > size {
+		log = slices.Delete(log, 0, len(log)-size)
+	}
+	return log
+}
+
+// newestFirst copies deliveries in reverse order
+func newestFirst(log []*model.WebhookDelivery) []model.WebhookDelivery {
+	deliveries := make([]model.WebhookDelivery, 0, len(log))
+	for i := len(log) - 1; i >= 0; i-- {
+		deliveries = append(deliveries, *log[i])
+	}
+	return deliveries
+}
+
+// newID returns a random hex ID
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
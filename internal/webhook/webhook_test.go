@@ -0,0 +1,374 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"order-pack-calculator/internal/model"
+	"order-pack-calculator/internal/storage"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// receiver is a webhook endpoint failing the first failures requests
+type receiver struct {
+	mu       sync.Mutex
+	failures int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	if len(rc.requests) <= rc.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (rc *receiver) count() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.requests)
+}
+
+// newTestDispatcher creates a dispatcher retrying quickly
+func newTestDispatcher(t *testing.T, maxAttempts int) *Dispatcher {
+	t.Helper()
+
+	d, err := New(nil, WithRetries(maxAttempts, time.Millisecond, 4*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create dispatcher: %v", err)
+	}
+	t.Cleanup(func() { d.Close(context.Background()) })
+	return d
+}
+
+// waitFor polls until cond holds or fails after a second
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPublishSignsDeliveries(t *testing.T) {
+	rc := &receiver{}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	d := newTestDispatcher(t, 1)
+	w, err := d.Add(server.URL, []string{EventPackSizesUpdated}, "s3cret")
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	d.Publish(EventOrderCalculated, model.CalculateResponse{}) // Not subscribed
+	d.Publish(EventPackSizesUpdated, model.PackSizesResponse{PackSizes: []int{250}, Version: 3})
+	waitFor(t, "delivery", func() bool {
+		deliveries := d.Deliveries()
+		return len(deliveries) == 1 && deliveries[0].Status == StatusDelivered
+	})
+
+	if rc.count() != 1 {
+		t.Fatalf("Expected 1 request, got %d", rc.count())
+	}
+	req, body := rc.requests[0], rc.bodies[0]
+
+	if got := req.Header.Get(HeaderEvent); got != EventPackSizesUpdated {
+		t.Errorf("Expected event header %s, got %s", EventPackSizesUpdated, got)
+	}
+	timestamp, _ := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	if got, want := req.Header.Get(HeaderSignature), Sign("s3cret", timestamp, body); got != want {
+		t.Errorf("Expected signature %s, got %s", want, got)
+	}
+
+	var event struct {
+		Event string                  `json:"event"`
+		Data  model.PackSizesResponse `json:"data"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if event.Event != EventPackSizesUpdated || event.Data.Version != 3 {
+		t.Errorf("Expected %s at version 3, got %s at version %d", EventPackSizesUpdated, event.Event, event.Data.Version)
+	}
+
+	delivery := d.Deliveries()[0]
+	if delivery.WebhookID != w.ID || delivery.Attempts != 1 || delivery.ResponseStatus != http.StatusNoContent {
+		t.Errorf("Unexpected delivery record: %+v", delivery)
+	}
+	if got := req.Header.Get(HeaderDelivery); got != delivery.ID {
+		t.Errorf("Expected delivery header %s, got %s", delivery.ID, got)
+	}
+}
+
+func TestPublishRetriesWithBackoff(t *testing.T) {
+	rc := &receiver{failures: 2}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	d := newTestDispatcher(t, 5)
+	d.Add(server.URL, []string{EventOrderCalculated}, "s3cret")
+
+	d.Publish(EventOrderCalculated, model.CalculateResponse{OrderQuantity: 251})
+	waitFor(t, "delivery", func() bool { return d.Deliveries()[0].Status == StatusDelivered })
+
+	if delivery := d.Deliveries()[0]; delivery.Attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", delivery.Attempts)
+	}
+	if len(d.DeadLetters()) != 0 {
+		t.Errorf("Expected no dead letters, got %d", len(d.DeadLetters()))
+	}
+
+	// Every attempt carries the same delivery ID
+	if rc.requests[0].Header.Get(HeaderDelivery) != rc.requests[2].Header.Get(HeaderDelivery) {
+		t.Error("Expected retries to keep the delivery ID")
+	}
+}
+
+func TestDeadLettersAndRedeliver(t *testing.T) {
+	rc := &receiver{failures: 3}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	d := newTestDispatcher(t, 3)
+	d.Add(server.URL, []string{EventPackSizesUpdated}, "s3cret")
+
+	d.Publish(EventPackSizesUpdated, model.PackSizesResponse{PackSizes: []int{250}})
+	waitFor(t, "dead letter", func() bool { return len(d.DeadLetters()) == 1 })
+
+	dead := d.DeadLetters()[0]
+	if dead.Status != StatusFailed || dead.Attempts != 3 || dead.ResponseStatus != http.StatusServiceUnavailable || dead.Error == "" {
+		t.Errorf("Unexpected dead letter: %+v", dead)
+	}
+
+	// The receiver has recovered, so redelivery succeeds
+	if _, err := d.Redeliver(dead.ID); err != nil {
+		t.Fatalf("Redeliver failed: %v", err)
+	}
+	waitFor(t, "redelivery", func() bool { return d.Deliveries()[0].Status == StatusDelivered })
+
+	if len(d.DeadLetters()) != 0 {
+		t.Errorf("Expected the dead letter to be taken off the list, got %d", len(d.DeadLetters()))
+	}
+	if len(d.Deliveries()) != 1 {
+		t.Errorf("Expected the redelivery to reuse the log entry, got %d entries", len(d.Deliveries()))
+	}
+	if _, err := d.Redeliver(dead.ID); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound for a redelivered dead letter, got %v", err)
+	}
+}
+
+func TestRedeliverRemovedWebhook(t *testing.T) {
+	d := newTestDispatcher(t, 1)
+	w, _ := d.Add("http://127.0.0.1:0/unreachable", []string{EventPackSizesUpdated}, "s3cret")
+
+	d.Publish(EventPackSizesUpdated, model.PackSizesResponse{})
+	waitFor(t, "dead letter", func() bool { return len(d.DeadLetters()) == 1 })
+
+	if err := d.Remove(w.ID); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := d.Redeliver(d.DeadLetters()[0].ID); err != ErrWebhookRemoved {
+		t.Errorf("Expected ErrWebhookRemoved, got %v", err)
+	}
+}
+
+func TestDeliveryLogSize(t *testing.T) {
+	server := httptest.NewServer(&receiver{})
+	defer server.Close()
+
+	d, _ := New(nil, WithLogSize(2))
+	defer d.Close(context.Background())
+	d.Add(server.URL, []string{EventOrderCalculated}, "s3cret")
+
+	for qty := 1; qty <= 3; qty++ {
+		d.Publish(EventOrderCalculated, model.CalculateResponse{OrderQuantity: qty})
+	}
+
+	deliveries := d.Deliveries()
+	if len(deliveries) != 2 {
+		t.Fatalf("Expected 2 deliveries, got %d", len(deliveries))
+	}
+	if data := deliveries[0].Payload.Data.(model.CalculateResponse); data.OrderQuantity != 3 {
+		t.Errorf("Expected the newest delivery first, got order quantity %d", data.OrderQuantity)
+	}
+}
+
+func TestQueueFull(t *testing.T) {
+	// The receiver holds the first delivery until released
+	received := make(chan struct{}, 3)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	d, _ := New(nil, WithRetries(1, 0, 0), WithQueue(1, 1))
+	defer d.Close(context.Background())
+	d.Add(server.URL, []string{EventOrderCalculated}, "s3cret")
+
+	d.Publish(EventOrderCalculated, model.CalculateResponse{OrderQuantity: 1})
+	<-received // The only worker is busy
+
+	d.Publish(EventOrderCalculated, model.CalculateResponse{OrderQuantity: 2}) // Queued
+	d.Publish(EventOrderCalculated, model.CalculateResponse{OrderQuantity: 3}) // Dropped
+
+	deadLetters := d.DeadLetters()
+	if len(deadLetters) != 1 || deadLetters[0].Payload.Data.(model.CalculateResponse).OrderQuantity != 3 {
+		t.Fatalf("Expected the third delivery as a dead letter, got %+v", deadLetters)
+	}
+	if !strings.Contains(deadLetters[0].Error, "queue is full") || d.Dropped() != 1 {
+		t.Errorf("Expected 1 delivery dropped from a full queue, got %d: %s", d.Dropped(), deadLetters[0].Error)
+	}
+
+	// Queued deliveries are still made
+	release <- struct{}{}
+	<-received
+	release <- struct{}{}
+	waitFor(t, "the queued delivery", func() bool {
+		return d.Deliveries()[1].Status == StatusDelivered
+	})
+}
+
+func TestRetriesDoNotHoldWorkers(t *testing.T) {
+	failing := &receiver{failures: 100}
+	failingServer := httptest.NewServer(failing)
+	defer failingServer.Close()
+	healthy := &receiver{}
+	healthyServer := httptest.NewServer(healthy)
+	defer healthyServer.Close()
+
+	// A single worker, and retries far apart
+	d, _ := New(nil, WithRetries(5, time.Minute, time.Minute), WithQueue(1, 10))
+	closed, cancel := context.WithCancel(context.Background())
+	cancel()
+	defer d.Close(closed) // Without waiting for the retries
+	d.Add(failingServer.URL, []string{EventOrderCalculated}, "s3cret")
+	d.Add(healthyServer.URL, []string{EventOrderCalculated}, "s3cret")
+
+	// The healthy endpoint gets every event while the failing one waits
+	// for its retries
+	for qty := 1; qty <= 3; qty++ {
+		d.Publish(EventOrderCalculated, model.CalculateResponse{OrderQuantity: qty})
+	}
+	waitFor(t, "deliveries to the healthy endpoint", func() bool { return healthy.count() == 3 })
+
+	if failing.count() != 3 {
+		t.Errorf("Expected 1 attempt per event at the failing endpoint, got %d", failing.count())
+	}
+	if len(d.DeadLetters()) != 0 {
+		t.Errorf("Expected no dead letters while retries are pending, got %d", len(d.DeadLetters()))
+	}
+}
+
+func TestWebhooksPersisted(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "webhooks.json")
+
+	d, err := New(storage.NewWebhookStore(file))
+	if err != nil {
+		t.Fatalf("Failed to create dispatcher: %v", err)
+	}
+	first, _ := d.Add("http://example.com/a", []string{EventPackSizesUpdated}, "one")
+	second, _ := d.Add("http://example.com/b", []string{EventOrderCalculated}, "two")
+	if err := d.Remove(first.ID); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	d.Close(context.Background())
+
+	reopened, err := New(storage.NewWebhookStore(file))
+	if err != nil {
+		t.Fatalf("Failed to reopen dispatcher: %v", err)
+	}
+	defer reopened.Close(context.Background())
+
+	webhooks := reopened.Webhooks()
+	if len(webhooks) != 1 || webhooks[0].ID != second.ID || webhooks[0].URL != "http://example.com/b" {
+		t.Errorf("Expected only the second webhook after reopening, got %+v", webhooks)
+	}
+	if _, err := reopened.Webhook(first.ID); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound for the removed webhook, got %v", err)
+	}
+}
+
+func TestCloseFinishesDeliveries(t *testing.T) {
+	rc := &receiver{failures: 1}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	d, _ := New(nil, WithRetries(3, 20*time.Millisecond, 20*time.Millisecond))
+	d.Add(server.URL, []string{EventPackSizesUpdated}, "s3cret")
+	d.Publish(EventPackSizesUpdated, model.PackSizesResponse{})
+
+	// The retry after the first failure is still made
+	if undelivered := d.Close(context.Background()); len(undelivered) != 0 {
+		t.Errorf("Expected every delivery made, got %+v", undelivered)
+	}
+	if rc.count() != 2 || d.Deliveries()[0].Status != StatusDelivered {
+		t.Errorf("Expected delivery on the second attempt, got %d requests: %+v", rc.count(), d.Deliveries())
+	}
+}
+
+func TestCloseReturnsUndelivered(t *testing.T) {
+	rc := &receiver{failures: 100}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	d, _ := New(nil, WithRetries(5, time.Minute, time.Minute))
+	d.Add(server.URL, []string{EventPackSizesUpdated}, "s3cret")
+	d.Publish(EventPackSizesUpdated, model.PackSizesResponse{})
+	waitFor(t, "the first attempt", func() bool { return rc.count() == 1 })
+
+	// The next retry is due long after the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	undelivered := d.Close(ctx)
+
+	if len(undelivered) != 1 || undelivered[0].Attempts != 1 || !strings.Contains(undelivered[0].Error, "shutdown") {
+		t.Fatalf("Expected 1 undelivered delivery after 1 attempt, got %+v", undelivered)
+	}
+	if len(d.DeadLetters()) != 1 {
+		t.Errorf("Expected 1 dead letter, got %d", len(d.DeadLetters()))
+	}
+}
+
+func TestPublishAfterClose(t *testing.T) {
+	rc := &receiver{}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	d, _ := New(nil)
+	d.Add(server.URL, []string{EventPackSizesUpdated}, "s3cret")
+	d.Close(context.Background())
+
+	d.Publish(EventPackSizesUpdated, model.PackSizesResponse{})
+	if len(d.Deliveries()) != 0 {
+		t.Errorf("Expected no deliveries after Close, got %d", len(d.Deliveries()))
+	}
+
+	// A nil dispatcher publishes nothing
+	var none *Dispatcher
+	none.Publish(EventPackSizesUpdated, model.PackSizesResponse{})
+}
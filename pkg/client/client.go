@@ -0,0 +1,300 @@
+// Package client is a Go client for the order pack calculator HTTP API.
+//
+//	c, err := client.New("http://localhost:8080", client.WithTimeout(5*time.Second))
+//	if err != nil {
+//		return err
+//	}
+//	resp, err := c.Calculate(ctx, client.CalculateRequest{OrderQuantity: 251})
+//
+// GET and PUT requests failing with a 5xx status or a transport error are
+// retried with exponential backoff. POST requests, such as calculations that
+// are recorded in the order history, are only retried when the connection
+// failed before the request was sent. Error responses are returned as
+// *Error, which carries the server's ErrorResponse.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults used by New
+const (
+	DefaultTimeout      = 30 * time.Second
+	DefaultMaxRetries   = 2
+	DefaultRetryBackoff = 100 * time.Millisecond
+)
+
+// Client calls the HTTP API of a running server
+// A Client is safe for concurrent use.
+type Client struct {
+	baseURL      *url.URL
+	apiKey       string
+	httpClient   *http.Client
+	maxRetries   int
+	retryBackoff time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests
+// Its Timeout applies to each attempt.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTimeout sets the timeout of each request attempt
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		httpClient := *c.httpClient
+		httpClient.Timeout = timeout
+		c.httpClient = &httpClient
+	}
+}
+
+// WithAPIKey sends key in the X-API-Key header of every request
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithRetries sets how many times a failed request is retried and the
+// backoff before the first retry, doubled for every further retry.
+// Zero retries disables retrying.
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryBackoff = backoff
+	}
+}
+
+// Error is an error response from the server
+type Error struct {
+	StatusCode int
+	Response   ErrorResponse
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("order pack calculator: %s (HTTP %d)", e.Response.Error, e.StatusCode)
+}
+
+// OrderQuery selects recorded orders for ListOrders
+// Zero values are left out of the request.
+type OrderQuery struct {
+	From           time.Time
+	To             time.Time
+	SKU            string
+	PackSetVersion *int64
+	Limit          int
+	Offset         int
+}
+
+// New creates a client for the server at baseURL, e.g. "http://localhost:8080"
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL %q: scheme must be http or https", baseURL)
+	}
+	u.Path = strings.TrimRight(u.Path, "/")
+
+	c := &Client{
+		baseURL:      u,
+		httpClient:   &http.Client{Timeout: DefaultTimeout},
+		maxRetries:   DefaultMaxRetries,
+		retryBackoff: DefaultRetryBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// GetPackSizes returns the current pack sizes
+func (c *Client) GetPackSizes(ctx context.Context) (*PackSizesResponse, error) {
+	var resp PackSizesResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/packs", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdatePackSizes replaces the pack sizes
+func (c *Client) UpdatePackSizes(ctx context.Context, packSizes []int) (*PackSizesResponse, error) {
+	var resp PackSizesResponse
+	req := PackSizesRequest{PackSizes: packSizes}
+	if err := c.do(ctx, http.MethodPut, "/api/v1/packs", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Calculate calculates the optimal packs for an order
+func (c *Client) Calculate(ctx context.Context, req CalculateRequest) (*CalculateResponse, error) {
+	var resp CalculateResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/calculate", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListOrders returns a page of recorded calculations, newest first
+func (c *Client) ListOrders(ctx context.Context, query OrderQuery) (*OrderListResponse, error) {
+	params := url.Values{}
+	if !query.From.IsZero() {
+		params.Set("from", query.From.Format(time.RFC3339))
+	}
+	if !query.To.IsZero() {
+		params.Set("to", query.To.Format(time.RFC3339))
+	}
+	if query.SKU != "" {
+		params.Set("sku", query.SKU)
+	}
+	if query.PackSetVersion != nil {
+		params.Set("version", strconv.FormatInt(*query.PackSetVersion, 10))
+	}
+	if query.Limit != 0 {
+		params.Set("limit", strconv.Itoa(query.Limit))
+	}
+	if query.Offset != 0 {
+		params.Set("offset", strconv.Itoa(query.Offset))
+	}
+
+	var resp OrderListResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/orders", params, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetOrder returns a single recorded calculation
+func (c *Client) GetOrder(ctx context.Context, id string) (*OrderRecord, error) {
+	var resp OrderRecord
+	if err := c.do(ctx, http.MethodGet, "/api/v1/orders/"+url.PathEscape(id), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// do sends a request, retrying server errors, and decodes the response
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	backoff := c.retryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := c.attempt(ctx, method, path, query, payload, out)
+		if err == nil || !retry || attempt >= c.maxRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// attempt sends a request once
+// Reports whether a failure may succeed if the request is repeated: server
+// errors and transport failures may, anything else is final. Requests that
+// are not idempotent are only repeated if they never reached the server.
+func (c *Client) attempt(ctx context.Context, method, path string, query url.Values, payload []byte, out any) (bool, error) {
+	// path is escaped already, e.g. an order ID containing a slash
+	u := *c.baseURL
+	u.RawPath = u.EscapedPath() + path
+	u.Path, _ = url.PathUnescape(u.RawPath)
+	u.RawQuery = query.Encode()
+
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	var sent atomic.Bool
+	req = req.WithContext(httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		WroteHeaders: func() { sent.Store(true) },
+	}))
+	repeatable := idempotent(method)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// Cancellation of the caller's context is final
+		return ctx.Err() == nil && (repeatable || !sent.Load()), err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ctx.Err() == nil && repeatable, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode >= 500 && repeatable, newError(resp.StatusCode, data)
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return false, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return false, nil
+}
+
+// idempotent reports whether sending a request twice has the same effect as
+// sending it once
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// newError builds an Error from a response body
+// Bodies that are not an ErrorResponse, such as plain-text "Method not
+// allowed", become the error message as-is.
+func newError(statusCode int, data []byte) *Error {
+	e := &Error{StatusCode: statusCode}
+	if err := json.Unmarshal(data, &e.Response); err != nil || e.Response.Error == "" {
+		e.Response.Error = strings.TrimSpace(string(data))
+	}
+	if e.Response.Error == "" {
+		e.Response.Error = http.StatusText(statusCode)
+	}
+	return e
+}
@@ -0,0 +1,255 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"order-pack-calculator/internal/handler"
+	"order-pack-calculator/internal/storage"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestServer serves the API backed by a real handler
+func newTestServer(t *testing.T, wrap func(http.Handler) http.Handler) *httptest.Server {
+	t.Helper()
+
+	history, err := storage.NewOrderHistory("")
+	if err != nil {
+		t.Fatalf("Failed to create order history: %v", err)
+	}
+	h := handler.NewHandler([]int{250, 500, 1000, 2000, 5000})
+	h.SetOrderHistory(history)
+
+	mux := h.NewRouter()
+
+	var root http.Handler = mux
+	if wrap != nil {
+		root = wrap(mux)
+	}
+
+	server := httptest.NewServer(root)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestClient(t *testing.T, server *httptest.Server, opts ...Option) *Client {
+	t.Helper()
+
+	c, err := New(server.URL, opts...)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return c
+}
+
+func TestPackSizes(t *testing.T) {
+	c := newTestClient(t, newTestServer(t, nil))
+	ctx := context.Background()
+
+	got, err := c.GetPackSizes(ctx)
+	if err != nil {
+		t.Fatalf("GetPackSizes() error = %v", err)
+	}
+	if len(got.PackSizes) != 5 {
+		t.Errorf("Expected 5 pack sizes, got %v", got.PackSizes)
+	}
+
+	updated, err := c.UpdatePackSizes(ctx, []int{23, 31, 53})
+	if err != nil {
+		t.Fatalf("UpdatePackSizes() error = %v", err)
+	}
+	if updated.Version != got.Version+1 || len(updated.PackSizes) != 3 {
+		t.Errorf("Unexpected update response: %+v", updated)
+	}
+}
+
+func TestCalculateAndOrders(t *testing.T) {
+	c := newTestClient(t, newTestServer(t, nil))
+	ctx := context.Background()
+
+	calc, err := c.Calculate(ctx, CalculateRequest{OrderQuantity: 12001, SKU: "A"})
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	if calc.TotalItems != 12250 || calc.TotalPacks != 4 {
+		t.Errorf("Unexpected calculation: %+v", calc)
+	}
+
+	list, err := c.ListOrders(ctx, OrderQuery{SKU: "A", From: time.Now().Add(-time.Hour), Limit: 10})
+	if err != nil {
+		t.Fatalf("ListOrders() error = %v", err)
+	}
+	if list.Total != 1 || list.Orders[0].ID != calc.OrderID {
+		t.Errorf("Unexpected order list: %+v", list)
+	}
+
+	rec, err := c.GetOrder(ctx, calc.OrderID)
+	if err != nil {
+		t.Fatalf("GetOrder() error = %v", err)
+	}
+	if rec.OrderQuantity != 12001 {
+		t.Errorf("Unexpected order: %+v", rec)
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	c := newTestClient(t, newTestServer(t, nil))
+
+	_, err := c.UpdatePackSizes(context.Background(), []int{250, -1})
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *Error, got %T: %v", err, err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", apiErr.StatusCode)
+	}
+	if apiErr.Response.Error != "Pack sizes must be positive integers" {
+		t.Errorf("Unexpected error message: %q", apiErr.Response.Error)
+	}
+}
+
+func TestRetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	failTwice := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) <= 2 {
+				http.Error(w, "temporarily unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	server := newTestServer(t, failTwice)
+
+	c := newTestClient(t, server, WithRetries(2, time.Millisecond))
+	packs, err := c.GetPackSizes(context.Background())
+	if err != nil {
+		t.Fatalf("GetPackSizes() error = %v", err)
+	}
+	if len(packs.PackSizes) != 5 || calls.Load() != 3 {
+		t.Errorf("Expected success on third attempt, got %+v after %d calls", packs, calls.Load())
+	}
+
+	// Calculations are recorded, so they are not repeated once sent
+	calls.Store(0)
+	_, err = c.Calculate(context.Background(), CalculateRequest{OrderQuantity: 251})
+	if calls.Load() != 1 {
+		t.Errorf("Expected a single calculation attempt, got %d", calls.Load())
+	}
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 error, got %v", err)
+	}
+	if apiErr != nil && apiErr.Response.Error != "temporarily unavailable" {
+		t.Errorf("Expected plain-text message, got %q", apiErr.Response.Error)
+	}
+}
+
+// refuseFirst fails its first round trip before sending anything
+type refuseFirst struct {
+	calls atomic.Int32
+}
+
+func (rt *refuseFirst) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt.calls.Add(1) == 1 {
+		return nil, errors.New("connection refused")
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestRetriesUnsentCalculations(t *testing.T) {
+	rt := &refuseFirst{}
+	c := newTestClient(t, newTestServer(t, nil), WithHTTPClient(&http.Client{Transport: rt}), WithRetries(2, time.Millisecond))
+
+	calc, err := c.Calculate(context.Background(), CalculateRequest{OrderQuantity: 251})
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	if calc.TotalItems != 500 || rt.calls.Load() != 2 {
+		t.Errorf("Expected success on the second attempt, got %+v after %d attempts", calc, rt.calls.Load())
+	}
+}
+
+func TestGetOrderEscapesID(t *testing.T) {
+	c := newTestClient(t, newTestServer(t, nil))
+
+	_, err := c.GetOrder(context.Background(), "../packs")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Response.Code != "order_not_found" {
+		t.Errorf("Expected order_not_found for an ID with a slash, got %v", err)
+	}
+}
+
+func TestNoRetryOnClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	count := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	c := newTestClient(t, newTestServer(t, count), WithRetries(3, time.Millisecond))
+	if _, err := c.Calculate(context.Background(), CalculateRequest{OrderQuantity: -1}); err == nil {
+		t.Fatal("Expected error for negative quantity")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected a single attempt, got %d", calls.Load())
+	}
+}
+
+func TestContextAndTimeout(t *testing.T) {
+	slow := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	server := newTestServer(t, slow)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	c := newTestClient(t, server)
+	if _, err := c.GetPackSizes(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+
+	c = newTestClient(t, server, WithTimeout(20*time.Millisecond), WithRetries(0, 0))
+	if _, err := c.GetPackSizes(context.Background()); err == nil {
+		t.Error("Expected timeout error")
+	}
+}
+
+func TestAPIKey(t *testing.T) {
+	var key atomic.Value
+	capture := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key.Store(r.Header.Get("X-API-Key"))
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	c := newTestClient(t, newTestServer(t, capture), WithAPIKey("secret"))
+	if _, err := c.GetPackSizes(context.Background()); err != nil {
+		t.Fatalf("GetPackSizes() error = %v", err)
+	}
+	if key.Load() != "secret" {
+		t.Errorf("Expected API key header, got %v", key.Load())
+	}
+}
+
+func TestNewInvalidURL(t *testing.T) {
+	for _, baseURL := range []string{"localhost:8080", "ftp://example.com", "://"} {
+		if _, err := New(baseURL); err == nil {
+			t.Errorf("New(%q) should fail", baseURL)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package client
+
+import "order-pack-calculator/internal/model"
+
+// Request and response types of the HTTP API
+// These alias the server's own types so the client can never drift from
+// what the server sends, while letting other modules name them.
+type (
+	PackSizesRequest  = model.PackSizesRequest
+	PackSizesResponse = model.PackSizesResponse
+	CalculateRequest  = model.CalculateRequest
+	CalculateResponse = model.CalculateResponse
+	PackBreakdown     = model.PackBreakdown
+	OrderRecord       = model.OrderRecord
+	OrderListResponse = model.OrderListResponse
+	ErrorResponse     = model.ErrorResponse
+	FieldError        = model.FieldError
+)
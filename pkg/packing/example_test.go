@@ -0,0 +1,41 @@
+package packing_test
+
+import (
+	"fmt"
+	"order-pack-calculator/pkg/packing"
+)
+
+func ExampleSolver_Solve() {
+	solver, err := packing.NewSolver([]int{250, 500, 1000, 2000, 5000})
+	if err != nil {
+		panic(err)
+	}
+
+	result, err := solver.Solve(12001)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, pack := range result.Packs {
+		fmt.Printf("%d x %d\n", pack.Quantity, pack.Size)
+	}
+	fmt.Println("excess:", result.Excess())
+	// Output:
+	// 2 x 5000
+	// 1 x 2000
+	// 1 x 250
+	// excess: 249
+}
+
+func ExampleWithMaxPacks() {
+	solver, err := packing.NewSolver([]int{250, 500}, packing.WithMaxPacks(2))
+	if err != nil {
+		panic(err)
+	}
+
+	if _, err := solver.Solve(1001); err == packing.ErrNoSolution {
+		fmt.Println("no combination of at most 2 packs covers 1001 items")
+	}
+	// Output:
+	// no combination of at most 2 packs covers 1001 items
+}
@@ -0,0 +1,406 @@
+// Package packing works out which whole packs to ship for an order.
+//
+// A Solver is configured once with the available pack sizes and then used
+// for any number of orders. It keeps the table of pack combinations it
+// builds and only extends it when a larger order arrives, so repeated
+// orders are answered without recalculating. By default it ships as few
+// items as possible and, among shipments of the same size, uses as few
+// packs as possible:
+//
+//	solver, err := packing.NewSolver([]int{250, 500, 1000, 2000, 5000})
+//	if err != nil {
+//		return err
+//	}
+//	result, err := solver.Solve(12001) // 2x5000 + 1x2000 + 1x250 = 12250 items
+//
+// Options select a different objective or add constraints such as a
+// maximum number of packs per order. The table grows with the order
+// quantity, so servers should bound it with WithMaxOrderQuantity or
+// WithMemoryBudget and use SolveContext to stop slow calculations.
+package packing
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"sync/atomic"
+)
+
+// Errors returned by NewSolver and Solve
+var (
+	// ErrNoPackSizes is returned by NewSolver when no pack sizes are given
+	ErrNoPackSizes = errors.New("packing: no pack sizes")
+
+	// ErrInvalidPackSize is returned by NewSolver for a pack size below 1
+	ErrInvalidPackSize = errors.New("packing: pack sizes must be positive")
+
+	// ErrPackSizeTooLarge is returned by NewSolver for a pack size above
+	// MaxPackSize
+	ErrPackSizeTooLarge = errors.New("packing: pack size exceeds the maximum")
+
+	// ErrNegativeQuantity is returned by Solve for an order quantity below 0
+	ErrNegativeQuantity = errors.New("packing: order quantity must not be negative")
+
+	// ErrNoSolution is returned by Solve when no combination of packs
+	// satisfies the solver's constraints
+	ErrNoSolution = errors.New("packing: no combination of packs satisfies the constraints")
+
+	// ErrQuantityTooLarge is returned by Solve for an order quantity above
+	// the solver's maximum
+	ErrQuantityTooLarge = errors.New("packing: order quantity exceeds the maximum")
+
+	// ErrMemoryBudget is returned by Solve when the table needed for an
+	// order would exceed the solver's memory budget
+	ErrMemoryBudget = errors.New("packing: order needs more memory than the budget allows")
+)
+
+// TableEntryBytes is the memory used by the solver table per item of order
+// quantity, for sizing WithMemoryBudget
+const TableEntryBytes = 12
+
+// MaxPackSize is the largest pack size a solver accepts, which leaves room
+// for orders of at least as many items within the table's int32 range
+const MaxPackSize = math.MaxInt32 / 2
+
+// Objective decides which of the combinations covering an order is best
+type Objective int
+
+const (
+	// MinimizeExcess ships as few items as possible, then as few packs as
+	// possible. This is the default.
+	MinimizeExcess Objective = iota
+
+	// MinimizePacks ships as few packs as possible, then as few items as
+	// possible.
+	MinimizePacks
+)
+
+// Option configures a Solver
+type Option func(*Solver)
+
+// WithObjective sets what the solver optimises for
+func WithObjective(objective Objective) Option {
+	return func(s *Solver) {
+		s.objective = objective
+	}
+}
+
+// WithMaxPacks limits the number of packs shipped for one order
+// A limit of 0 or less means no limit.
+func WithMaxPacks(maxPacks int) Option {
+	return func(s *Solver) {
+		s.maxPacks = maxPacks
+	}
+}
+
+// WithMaxExcess limits how many items beyond the order quantity may be
+// shipped. A negative limit means no limit.
+func WithMaxExcess(maxExcess int) Option {
+	return func(s *Solver) {
+		s.maxExcess = maxExcess
+	}
+}
+
+// WithMaxOrderQuantity rejects orders above maxQty with ErrQuantityTooLarge
+// A limit of 0 or less means no limit.
+func WithMaxOrderQuantity(maxQty int) Option {
+	return func(s *Solver) {
+		s.maxOrderQty = maxQty
+	}
+}
+
+// WithMemoryBudget limits the memory of the solver table to about maxBytes
+// The old table is kept until a larger one is built, so the budget covers
+// both while the table grows. Orders needing a larger table fail with
+// ErrMemoryBudget. A budget of 0 or less means no limit.
+func WithMemoryBudget(maxBytes int64) Option {
+	return func(s *Solver) {
+		s.memoryBudget = maxBytes
+	}
+}
+
+// Pack is a number of packs of one size
+type Pack struct {
+	Size     int
+	Quantity int
+}
+
+// Result is the combination of packs chosen for an order
+type Result struct {
+	OrderQuantity int
+	Packs         []Pack // Largest size first, only sizes used
+	TotalItems    int
+	TotalPacks    int
+}
+
+// Excess returns the number of items shipped beyond the order quantity
+func (r Result) Excess() int {
+	return r.TotalItems - r.OrderQuantity
+}
+
+// Solver calculates pack combinations for a fixed set of pack sizes
+// A Solver is safe for concurrent use.
+type Solver struct {
+	sizes        []int // Distinct, largest first
+	objective    Objective
+	maxPacks     int
+	maxExcess    int
+	maxOrderQty  int
+	memoryBudget int64
+
+	extending chan struct{}         // Held while extending the table
+	table     atomic.Pointer[table] // Shared by all orders, only ever extended
+}
+
+// NewSolver creates a solver for the given pack sizes
+// Duplicate sizes are ignored.
+func NewSolver(packSizes []int, opts ...Option) (*Solver, error) {
+	if len(packSizes) == 0 {
+		return nil, ErrNoPackSizes
+	}
+
+	seen := make(map[int]bool)
+	sizes := []int{}
+	for _, size := range packSizes {
+		if size <= 0 {
+			return nil, ErrInvalidPackSize
+		}
+		if size > MaxPackSize {
+			return nil, ErrPackSizeTooLarge
+		}
+		if !seen[size] {
+			seen[size] = true
+			sizes = append(sizes, size)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+
+	s := &Solver{
+		sizes:     sizes,
+		objective: MinimizeExcess,
+		maxExcess: -1,
+		extending: make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s, nil
+}
+
+// PackSizes returns the distinct pack sizes, largest first
+func (s *Solver) PackSizes() []int {
+	sizes := make([]int, len(s.sizes))
+	copy(sizes, s.sizes)
+	return sizes
+}
+
+// TableSize returns the number of totals covered by the table shared by all
+// orders, which grows with the largest order solved so far
+func (s *Solver) TableSize() int {
+	if t := s.table.Load(); t != nil {
+		return t.size()
+	}
+	return 0
+}
+
+// Solve returns the best combination of packs for an order
+// An order of 0 items ships nothing.
+func (s *Solver) Solve(orderQty int) (Result, error) {
+	return s.SolveContext(context.Background(), orderQty)
+}
+
+// SolveContext is like Solve but gives up with the context's error once ctx
+// is done, whether waiting for or extending the table
+func (s *Solver) SolveContext(ctx context.Context, orderQty int) (Result, error) {
+	if orderQty < 0 {
+		return Result{}, ErrNegativeQuantity
+	}
+	if orderQty == 0 {
+		return Result{OrderQuantity: 0, Packs: []Pack{}}, nil
+	}
+	if (s.maxOrderQty > 0 && orderQty > s.maxOrderQty) || orderQty > math.MaxInt32-s.sizes[0] {
+		return Result{}, ErrQuantityTooLarge
+	}
+
+	// Removing any pack from a shipment of orderQty+largest items or more
+	// still covers the order with fewer packs and items, so the best
+	// shipment is always below that bound
+	upperBound := orderQty + s.sizes[0] - 1
+	t, err := s.tableFor(ctx, upperBound)
+	if err != nil {
+		return Result{}, err
+	}
+
+	best := s.choose(t, orderQty, upperBound)
+	if best < 0 {
+		return Result{}, ErrNoSolution
+	}
+
+	return t.result(s.sizes, orderQty, best), nil
+}
+
+// tableFor returns a table covering totals up to upperBound, extending the
+// shared table first if it is too small. Tables are never modified once
+// published, so callers read them without holding a lock.
+func (s *Solver) tableFor(ctx context.Context, upperBound int) (*table, error) {
+	if t := s.table.Load(); t != nil && t.size() > upperBound {
+		return t, nil
+	}
+
+	size := upperBound + 1
+	if s.memoryBudget > 0 && int64(size)*TableEntryBytes > s.memoryBudget {
+		return nil, ErrMemoryBudget
+	}
+
+	select {
+	case s.extending <- struct{}{}:
+		defer func() { <-s.extending }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	// Another order may have extended the table while waiting
+	t := s.table.Load()
+	if t != nil && t.size() > upperBound {
+		return t, nil
+	}
+
+	// The current table stays in memory while it is copied into the new
+	// one, so both count against the budget
+	maxSize := math.MaxInt32
+	if s.memoryBudget > 0 {
+		maxSize = int(s.memoryBudget / TableEntryBytes)
+		if t != nil {
+			maxSize -= t.size()
+		}
+		if size > maxSize {
+			return nil, ErrMemoryBudget
+		}
+	}
+
+	// Grow at least geometrically so a run of increasing orders does not
+	// copy the table every time
+	if t != nil && size < 2*t.size() {
+		size = min(2*t.size(), maxSize)
+	}
+
+	t, err := t.extend(ctx, s.sizes, size)
+	if err != nil {
+		return nil, err
+	}
+	s.table.Store(t)
+	return t, nil
+}
+
+// choose picks the best reachable total for an order from the table
+// Returns -1 if no total satisfies the constraints.
+func (s *Solver) choose(t *table, orderQty, upperBound int) int {
+	best := -1
+	for total := orderQty; total <= upperBound; total++ {
+		if s.maxExcess >= 0 && total-orderQty > s.maxExcess {
+			break
+		}
+
+		count := t.count[total]
+		if count == 0 || (s.maxPacks > 0 && count > s.maxPacks) {
+			continue
+		}
+
+		switch s.objective {
+		case MinimizePacks:
+			if best < 0 || count < t.count[best] {
+				best = total
+			}
+		default:
+			// The first reachable total has the least excess, and the
+			// table holds its fewest packs
+			return total
+		}
+	}
+
+	return best
+}
+
+// cancelCheckInterval is how many totals extend calculates between checks
+// for cancellation
+const cancelCheckInterval = 1 << 16
+
+// table holds, for every total up to its size, the fewest packs adding up
+// to exactly that total and the last pack used to get there
+type table struct {
+	count []int   // Fewest packs for each total; 0 if unreachable
+	last  []int32 // Index into sizes of the last pack added
+}
+
+// size returns the number of totals covered, starting at 0
+func (t *table) size() int {
+	return len(t.count)
+}
+
+// extend returns a new table covering totals below size, reusing the
+// entries already calculated in t, which may be nil. Entries only depend on
+// smaller totals, so existing ones stay valid.
+// Sizes are tried largest first and only replaced by strictly fewer packs,
+// which makes ties resolve towards larger packs.
+func (t *table) extend(ctx context.Context, sizes []int, size int) (*table, error) {
+	next := &table{
+		count: make([]int, size),
+		last:  make([]int32, size),
+	}
+
+	start := 1
+	if t != nil {
+		copy(next.count, t.count)
+		copy(next.last, t.last)
+		start = t.size()
+	}
+
+	for total := start; total < size; total++ {
+		if total%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
+		for i, size := range sizes {
+			if total < size {
+				continue
+			}
+			prev := total - size
+			if prev > 0 && next.count[prev] == 0 {
+				continue
+			}
+
+			if count := next.count[prev] + 1; next.count[total] == 0 || count < next.count[total] {
+				next.count[total] = count
+				next.last[total] = int32(i)
+			}
+		}
+	}
+
+	return next, nil
+}
+
+// result walks back from total to collect the packs used
+func (t *table) result(sizes []int, orderQty, total int) Result {
+	quantities := make([]int, len(sizes))
+	for remaining := total; remaining > 0; remaining -= sizes[t.last[remaining]] {
+		quantities[t.last[remaining]]++
+	}
+
+	res := Result{
+		OrderQuantity: orderQty,
+		Packs:         []Pack{},
+		TotalItems:    total,
+		TotalPacks:    t.count[total],
+	}
+	for i, qty := range quantities {
+		if qty > 0 {
+			res.Packs = append(res.Packs, Pack{Size: sizes[i], Quantity: qty})
+		}
+	}
+
+	return res
+}
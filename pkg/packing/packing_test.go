@@ -0,0 +1,341 @@
+package packing
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+var defaultSizes = []int{250, 500, 1000, 2000, 5000}
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		name      string
+		packSizes []int
+		orderQty  int
+		wantPacks []Pack
+		wantTotal int
+	}{
+		{
+			name:      "order 1 item",
+			packSizes: defaultSizes,
+			orderQty:  1,
+			wantPacks: []Pack{{Size: 250, Quantity: 1}},
+			wantTotal: 250,
+		},
+		{
+			name:      "prefer fewer packs over more packs same items",
+			packSizes: defaultSizes,
+			orderQty:  251,
+			wantPacks: []Pack{{Size: 500, Quantity: 1}},
+			wantTotal: 500,
+		},
+		{
+			name:      "combination of packs",
+			packSizes: defaultSizes,
+			orderQty:  501,
+			wantPacks: []Pack{{Size: 500, Quantity: 1}, {Size: 250, Quantity: 1}},
+			wantTotal: 750,
+		},
+		{
+			name:      "large order",
+			packSizes: defaultSizes,
+			orderQty:  12001,
+			wantPacks: []Pack{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 250, Quantity: 1}},
+			wantTotal: 12250,
+		},
+		{
+			name:      "order zero",
+			packSizes: defaultSizes,
+			orderQty:  0,
+			wantPacks: []Pack{},
+			wantTotal: 0,
+		},
+		{
+			name:      "duplicate and unsorted pack sizes",
+			packSizes: []int{500, 250, 500, 1000},
+			orderQty:  251,
+			wantPacks: []Pack{{Size: 500, Quantity: 1}},
+			wantTotal: 500,
+		},
+		{
+			name:      "large order with small pack sizes",
+			packSizes: []int{23, 31, 53},
+			orderQty:  500000,
+			wantPacks: []Pack{{Size: 53, Quantity: 9429}, {Size: 31, Quantity: 7}, {Size: 23, Quantity: 2}},
+			wantTotal: 500000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			solver, err := NewSolver(tt.packSizes)
+			if err != nil {
+				t.Fatalf("NewSolver() error = %v", err)
+			}
+
+			got, err := solver.Solve(tt.orderQty)
+			if err != nil {
+				t.Fatalf("Solve() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Packs, tt.wantPacks) {
+				t.Errorf("Solve() packs = %v, want %v", got.Packs, tt.wantPacks)
+			}
+			if got.TotalItems != tt.wantTotal {
+				t.Errorf("Solve() total = %d, want %d", got.TotalItems, tt.wantTotal)
+			}
+			if got.Excess() != tt.wantTotal-tt.orderQty {
+				t.Errorf("Excess() = %d, want %d", got.Excess(), tt.wantTotal-tt.orderQty)
+			}
+		})
+	}
+}
+
+func TestSolveObjectivesAndConstraints(t *testing.T) {
+	tests := []struct {
+		name      string
+		packSizes []int
+		opts      []Option
+		orderQty  int
+		wantPacks []Pack
+		wantErr   error
+	}{
+		{
+			name:      "minimize excess uses more packs for less excess",
+			packSizes: []int{3, 5},
+			orderQty:  9,
+			wantPacks: []Pack{{Size: 3, Quantity: 3}},
+		},
+		{
+			name:      "minimize packs accepts more excess for fewer packs",
+			packSizes: []int{3, 5},
+			opts:      []Option{WithObjective(MinimizePacks)},
+			orderQty:  9,
+			wantPacks: []Pack{{Size: 5, Quantity: 2}},
+		},
+		{
+			name:      "max packs forces more excess",
+			packSizes: []int{250, 500, 1000},
+			opts:      []Option{WithMaxPacks(2)},
+			orderQty:  1750,
+			wantPacks: []Pack{{Size: 1000, Quantity: 2}},
+		},
+		{
+			name:      "max packs cannot be met",
+			packSizes: []int{250, 500},
+			opts:      []Option{WithMaxPacks(2)},
+			orderQty:  1001,
+			wantErr:   ErrNoSolution,
+		},
+		{
+			name:      "max excess within limit",
+			packSizes: defaultSizes,
+			opts:      []Option{WithMaxExcess(249)},
+			orderQty:  1001,
+			wantPacks: []Pack{{Size: 1000, Quantity: 1}, {Size: 250, Quantity: 1}},
+		},
+		{
+			name:      "max excess cannot be met",
+			packSizes: defaultSizes,
+			opts:      []Option{WithMaxExcess(100)},
+			orderQty:  1001,
+			wantErr:   ErrNoSolution,
+		},
+		{
+			name:      "negative order quantity",
+			packSizes: defaultSizes,
+			orderQty:  -5,
+			wantErr:   ErrNegativeQuantity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			solver, err := NewSolver(tt.packSizes, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewSolver() error = %v", err)
+			}
+
+			got, err := solver.Solve(tt.orderQty)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Solve() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got.Packs, tt.wantPacks) {
+				t.Errorf("Solve() packs = %v, want %v", got.Packs, tt.wantPacks)
+			}
+		})
+	}
+}
+
+func TestSolveReusesTable(t *testing.T) {
+	shared, err := NewSolver([]int{23, 31, 53})
+	if err != nil {
+		t.Fatalf("NewSolver() error = %v", err)
+	}
+
+	// Orders in increasing, decreasing and mixed order must match a fresh
+	// solver for each order
+	for _, qty := range []int{1, 100, 5000, 263, 20000, 7, 19999} {
+		fresh, _ := NewSolver([]int{23, 31, 53})
+		want, _ := fresh.Solve(qty)
+
+		got, err := shared.Solve(qty)
+		if err != nil {
+			t.Fatalf("Solve(%d) error = %v", qty, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Solve(%d) = %+v, want %+v", qty, got, want)
+		}
+	}
+
+	if size := shared.table.Load().size(); size < 20000 {
+		t.Errorf("table size = %d, want at least 20000", size)
+	}
+}
+
+func TestSolveConcurrent(t *testing.T) {
+	solver, err := NewSolver(defaultSizes)
+	if err != nil {
+		t.Fatalf("NewSolver() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			qty := 12001 + i*1000
+			got, err := solver.Solve(qty)
+			if err != nil {
+				t.Errorf("Solve(%d) error = %v", qty, err)
+				return
+			}
+			if got.TotalItems < qty || got.Excess() >= 250 {
+				t.Errorf("Solve(%d) total = %d, want within 249 of the order", qty, got.TotalItems)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestSolveLimits(t *testing.T) {
+	solver, err := NewSolver(defaultSizes, WithMaxOrderQuantity(100000), WithMemoryBudget(1<<20))
+	if err != nil {
+		t.Fatalf("NewSolver() error = %v", err)
+	}
+
+	if _, err := solver.Solve(100001); !errors.Is(err, ErrQuantityTooLarge) {
+		t.Errorf("Solve() above the maximum error = %v, want %v", err, ErrQuantityTooLarge)
+	}
+
+	// 1 MiB holds a table for about 87000 items
+	if _, err := solver.Solve(90000); !errors.Is(err, ErrMemoryBudget) {
+		t.Errorf("Solve() above the memory budget error = %v, want %v", err, ErrMemoryBudget)
+	}
+	if _, err := solver.Solve(80000); err != nil {
+		t.Errorf("Solve() within the limits error = %v", err)
+	}
+	if size := solver.table.Load().size(); int64(size)*TableEntryBytes > 1<<20 {
+		t.Errorf("table size %d exceeds the memory budget", size)
+	}
+
+	// The table being replaced counts against the budget while the new one
+	// is built: a table for 60000 items fits on its own, but not next to
+	// the one grown for 40000
+	growing, _ := NewSolver(defaultSizes, WithMemoryBudget(1<<20))
+	if _, err := growing.Solve(20000); err != nil {
+		t.Fatalf("Solve() error = %v", err)
+	}
+	if _, err := growing.Solve(40000); err != nil {
+		t.Fatalf("Solve() error = %v", err)
+	}
+	if size := growing.TableSize(); int64(size+25000)*TableEntryBytes > 1<<20 {
+		t.Errorf("table size %d and the one it replaced exceed the memory budget", size)
+	}
+	if _, err := growing.Solve(60000); !errors.Is(err, ErrMemoryBudget) {
+		t.Errorf("Solve() needing two tables above the budget error = %v, want %v", err, ErrMemoryBudget)
+	}
+
+	// Quantities that would overflow the table are rejected without limits
+	unlimited, _ := NewSolver(defaultSizes)
+	if _, err := unlimited.Solve(int(^uint(0) >> 1)); !errors.Is(err, ErrQuantityTooLarge) {
+		t.Errorf("Solve() of the largest int error = %v, want %v", err, ErrQuantityTooLarge)
+	}
+}
+
+func TestSolveContextCancelled(t *testing.T) {
+	solver, err := NewSolver([]int{23, 31, 53})
+	if err != nil {
+		t.Fatalf("NewSolver() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := solver.SolveContext(ctx, 10000000); !errors.Is(err, context.Canceled) {
+		t.Fatalf("SolveContext() error = %v, want %v", err, context.Canceled)
+	}
+	if solver.table.Load() != nil {
+		t.Error("Expected a cancelled extension not to be published")
+	}
+
+	// The solver still works for later orders
+	if got, err := solver.Solve(263); err != nil || got.TotalItems != 263 {
+		t.Errorf("Solve() = %+v, %v, want 263 items", got, err)
+	}
+}
+
+func TestNewSolverInvalid(t *testing.T) {
+	if _, err := NewSolver(nil); !errors.Is(err, ErrNoPackSizes) {
+		t.Errorf("NewSolver(nil) error = %v, want %v", err, ErrNoPackSizes)
+	}
+	if _, err := NewSolver([]int{250, 0}); !errors.Is(err, ErrInvalidPackSize) {
+		t.Errorf("NewSolver() error = %v, want %v", err, ErrInvalidPackSize)
+	}
+	if _, err := NewSolver([]int{250, MaxPackSize + 1}); !errors.Is(err, ErrPackSizeTooLarge) {
+		t.Errorf("NewSolver() error = %v, want %v", err, ErrPackSizeTooLarge)
+	}
+
+	// The largest pack size still leaves room for orders
+	solver, err := NewSolver([]int{MaxPackSize}, WithMemoryBudget(1<<20))
+	if err != nil {
+		t.Fatalf("NewSolver() with the largest pack size error = %v", err)
+	}
+	if _, err := solver.Solve(MaxPackSize); !errors.Is(err, ErrMemoryBudget) {
+		t.Errorf("Solve() error = %v, want %v", err, ErrMemoryBudget)
+	}
+}
+
+func TestPackSizes(t *testing.T) {
+	input := []int{500, 250, 500}
+	solver, err := NewSolver(input)
+	if err != nil {
+		t.Fatalf("NewSolver() error = %v", err)
+	}
+
+	sizes := solver.PackSizes()
+	if !reflect.DeepEqual(sizes, []int{500, 250}) {
+		t.Errorf("PackSizes() = %v, want [500 250]", sizes)
+	}
+
+	// The solver keeps its own copy
+	sizes[0] = 1
+	input[0] = 1
+	if got := solver.PackSizes(); got[0] != 500 {
+		t.Errorf("PackSizes() = %v after modifying copies, want [500 250]", got)
+	}
+}
+
+func TestTableSize(t *testing.T) {
+	s, _ := NewSolver([]int{250, 500})
+	if size := s.TableSize(); size != 0 {
+		t.Errorf("TableSize() before solving = %d, want 0", size)
+	}
+
+	s.Solve(1000)
+	if size := s.TableSize(); size < 1000+500 {
+		t.Errorf("TableSize() = %d, want at least 1500", size)
+	}
+}
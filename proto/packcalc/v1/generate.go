@@ -0,0 +1,5 @@
+// Package packcalcv1 is the gRPC API of the order pack calculator,
+// generated from packcalc.proto. It mirrors the /api/v1 HTTP endpoints.
+package packcalcv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative packcalc/v1/packcalc.proto
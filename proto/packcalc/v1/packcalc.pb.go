@@ -0,0 +1,674 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.4
+// 	protoc        (unknown)
+// source: packcalc/v1/packcalc.proto
+
+package packcalcv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetPackSizesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPackSizesRequest) Reset() {
+	*x = GetPackSizesRequest{}
+	mi := &file_packcalc_v1_packcalc_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPackSizesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPackSizesRequest) ProtoMessage() {}
+
+func (x *GetPackSizesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_packcalc_v1_packcalc_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPackSizesRequest.ProtoReflect.Descriptor instead.
+func (*GetPackSizesRequest) Descriptor() ([]byte, []int) {
+	return file_packcalc_v1_packcalc_proto_rawDescGZIP(), []int{0}
+}
+
+type UpdatePackSizesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PackSizes     []int64                `protobuf:"varint,1,rep,packed,name=pack_sizes,json=packSizes,proto3" json:"pack_sizes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePackSizesRequest) Reset() {
+	*x = UpdatePackSizesRequest{}
+	mi := &file_packcalc_v1_packcalc_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePackSizesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePackSizesRequest) ProtoMessage() {}
+
+func (x *UpdatePackSizesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_packcalc_v1_packcalc_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePackSizesRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePackSizesRequest) Descriptor() ([]byte, []int) {
+	return file_packcalc_v1_packcalc_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *UpdatePackSizesRequest) GetPackSizes() []int64 {
+	if x != nil {
+		return x.PackSizes
+	}
+	return nil
+}
+
+type PackSizes struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	PackSizes []int64                `protobuf:"varint,1,rep,packed,name=pack_sizes,json=packSizes,proto3" json:"pack_sizes,omitempty"`
+	// Pack set version, bumped on every change.
+	Version       int64 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PackSizes) Reset() {
+	*x = PackSizes{}
+	mi := &file_packcalc_v1_packcalc_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PackSizes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PackSizes) ProtoMessage() {}
+
+func (x *PackSizes) ProtoReflect() protoreflect.Message {
+	mi := &file_packcalc_v1_packcalc_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PackSizes.ProtoReflect.Descriptor instead.
+func (*PackSizes) Descriptor() ([]byte, []int) {
+	return file_packcalc_v1_packcalc_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PackSizes) GetPackSizes() []int64 {
+	if x != nil {
+		return x.PackSizes
+	}
+	return nil
+}
+
+func (x *PackSizes) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type CalculateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Required; an unset quantity is an error rather than an order of 0.
+	OrderQuantity *int64 `protobuf:"varint,1,opt,name=order_quantity,json=orderQuantity,proto3,oneof" json:"order_quantity,omitempty"`
+	ClientOrderId string `protobuf:"bytes,2,opt,name=client_order_id,json=clientOrderId,proto3" json:"client_order_id,omitempty"`
+	Sku           string `protobuf:"bytes,3,opt,name=sku,proto3" json:"sku,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CalculateRequest) Reset() {
+	*x = CalculateRequest{}
+	mi := &file_packcalc_v1_packcalc_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CalculateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalculateRequest) ProtoMessage() {}
+
+func (x *CalculateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_packcalc_v1_packcalc_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalculateRequest.ProtoReflect.Descriptor instead.
+func (*CalculateRequest) Descriptor() ([]byte, []int) {
+	return file_packcalc_v1_packcalc_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CalculateRequest) GetOrderQuantity() int64 {
+	if x != nil && x.OrderQuantity != nil {
+		return *x.OrderQuantity
+	}
+	return 0
+}
+
+func (x *CalculateRequest) GetClientOrderId() string {
+	if x != nil {
+		return x.ClientOrderId
+	}
+	return ""
+}
+
+func (x *CalculateRequest) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+type PackBreakdown struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Size          int64                  `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	Quantity      int64                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PackBreakdown) Reset() {
+	*x = PackBreakdown{}
+	mi := &file_packcalc_v1_packcalc_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PackBreakdown) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PackBreakdown) ProtoMessage() {}
+
+func (x *PackBreakdown) ProtoReflect() protoreflect.Message {
+	mi := &file_packcalc_v1_packcalc_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PackBreakdown.ProtoReflect.Descriptor instead.
+func (*PackBreakdown) Descriptor() ([]byte, []int) {
+	return file_packcalc_v1_packcalc_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PackBreakdown) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *PackBreakdown) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type CalculateResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Set when order history is enabled.
+	OrderId       string `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	ClientOrderId string `protobuf:"bytes,2,opt,name=client_order_id,json=clientOrderId,proto3" json:"client_order_id,omitempty"`
+	Sku           string `protobuf:"bytes,3,opt,name=sku,proto3" json:"sku,omitempty"`
+	OrderQuantity int64  `protobuf:"varint,4,opt,name=order_quantity,json=orderQuantity,proto3" json:"order_quantity,omitempty"`
+	// Largest packs first.
+	Packs         []*PackBreakdown `protobuf:"bytes,5,rep,name=packs,proto3" json:"packs,omitempty"`
+	TotalItems    int64            `protobuf:"varint,6,opt,name=total_items,json=totalItems,proto3" json:"total_items,omitempty"`
+	TotalPacks    int64            `protobuf:"varint,7,opt,name=total_packs,json=totalPacks,proto3" json:"total_packs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CalculateResponse) Reset() {
+	*x = CalculateResponse{}
+	mi := &file_packcalc_v1_packcalc_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CalculateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalculateResponse) ProtoMessage() {}
+
+func (x *CalculateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_packcalc_v1_packcalc_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalculateResponse.ProtoReflect.Descriptor instead.
+func (*CalculateResponse) Descriptor() ([]byte, []int) {
+	return file_packcalc_v1_packcalc_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CalculateResponse) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *CalculateResponse) GetClientOrderId() string {
+	if x != nil {
+		return x.ClientOrderId
+	}
+	return ""
+}
+
+func (x *CalculateResponse) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+func (x *CalculateResponse) GetOrderQuantity() int64 {
+	if x != nil {
+		return x.OrderQuantity
+	}
+	return 0
+}
+
+func (x *CalculateResponse) GetPacks() []*PackBreakdown {
+	if x != nil {
+		return x.Packs
+	}
+	return nil
+}
+
+func (x *CalculateResponse) GetTotalItems() int64 {
+	if x != nil {
+		return x.TotalItems
+	}
+	return 0
+}
+
+func (x *CalculateResponse) GetTotalPacks() int64 {
+	if x != nil {
+		return x.TotalPacks
+	}
+	return 0
+}
+
+// Error describes why an order in a batch could not be calculated.
+type Error struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Error code as in the HTTP API, e.g. "order_quantity_too_large".
+	Code    string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// Path of the offending field, e.g. "order_quantity".
+	Field         string `protobuf:"bytes,3,opt,name=field,proto3" json:"field,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_packcalc_v1_packcalc_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_packcalc_v1_packcalc_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_packcalc_v1_packcalc_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Error) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Error) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Error) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+type CalculateBatchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Position of the order in the request stream, starting at 0.
+	Index int64 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// Types that are valid to be assigned to Result:
+	//
+	//	*CalculateBatchResponse_Response
+	//	*CalculateBatchResponse_Error
+	Result        isCalculateBatchResponse_Result `protobuf_oneof:"result"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CalculateBatchResponse) Reset() {
+	*x = CalculateBatchResponse{}
+	mi := &file_packcalc_v1_packcalc_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CalculateBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalculateBatchResponse) ProtoMessage() {}
+
+func (x *CalculateBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_packcalc_v1_packcalc_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalculateBatchResponse.ProtoReflect.Descriptor instead.
+func (*CalculateBatchResponse) Descriptor() ([]byte, []int) {
+	return file_packcalc_v1_packcalc_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CalculateBatchResponse) GetIndex() int64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *CalculateBatchResponse) GetResult() isCalculateBatchResponse_Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *CalculateBatchResponse) GetResponse() *CalculateResponse {
+	if x != nil {
+		if x, ok := x.Result.(*CalculateBatchResponse_Response); ok {
+			return x.Response
+		}
+	}
+	return nil
+}
+
+func (x *CalculateBatchResponse) GetError() *Error {
+	if x != nil {
+		if x, ok := x.Result.(*CalculateBatchResponse_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+type isCalculateBatchResponse_Result interface {
+	isCalculateBatchResponse_Result()
+}
+
+type CalculateBatchResponse_Response struct {
+	Response *CalculateResponse `protobuf:"bytes,2,opt,name=response,proto3,oneof"`
+}
+
+type CalculateBatchResponse_Error struct {
+	Error *Error `protobuf:"bytes,3,opt,name=error,proto3,oneof"`
+}
+
+func (*CalculateBatchResponse_Response) isCalculateBatchResponse_Result() {}
+
+func (*CalculateBatchResponse_Error) isCalculateBatchResponse_Result() {}
+
+var File_packcalc_v1_packcalc_proto protoreflect.FileDescriptor
+
+var file_packcalc_v1_packcalc_proto_rawDesc = string([]byte{
+	0x0a, 0x1a, 0x70, 0x61, 0x63, 0x6b, 0x63, 0x61, 0x6c, 0x63, 0x2f, 0x76, 0x31, 0x2f, 0x70, 0x61,
+	0x63, 0x6b, 0x63, 0x61, 0x6c, 0x63, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0b, 0x70, 0x61,
+	0x63, 0x6b, 0x63, 0x61, 0x6c, 0x63, 0x2e, 0x76, 0x31, 0x22, 0x15, 0x0a, 0x13, 0x47, 0x65, 0x74,
+	0x50, 0x61, 0x63, 0x6b, 0x53, 0x69, 0x7a, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x22, 0x37, 0x0a, 0x16, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x61, 0x63, 0x6b, 0x53, 0x69,
+	0x7a, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61,
+	0x63, 0x6b, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x03, 0x52, 0x09,
+	0x70, 0x61, 0x63, 0x6b, 0x53, 0x69, 0x7a, 0x65, 0x73, 0x22, 0x44, 0x0a, 0x09, 0x50, 0x61, 0x63,
+	0x6b, 0x53, 0x69, 0x7a, 0x65, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x63, 0x6b, 0x5f, 0x73,
+	0x69, 0x7a, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x03, 0x52, 0x09, 0x70, 0x61, 0x63, 0x6b,
+	0x53, 0x69, 0x7a, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22,
+	0x8b, 0x01, 0x0a, 0x10, 0x43, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x2a, 0x0a, 0x0e, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x5f, 0x71, 0x75,
+	0x61, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x0d,
+	0x6f, 0x72, 0x64, 0x65, 0x72, 0x51, 0x75, 0x61, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x88, 0x01, 0x01,
+	0x12, 0x26, 0x0a, 0x0f, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x6f, 0x72, 0x64, 0x65, 0x72,
+	0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63, 0x6c, 0x69, 0x65, 0x6e,
+	0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x49, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x6b, 0x75, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x6b, 0x75, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x6f,
+	0x72, 0x64, 0x65, 0x72, 0x5f, 0x71, 0x75, 0x61, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x22, 0x3f, 0x0a,
+	0x0d, 0x50, 0x61, 0x63, 0x6b, 0x42, 0x72, 0x65, 0x61, 0x6b, 0x64, 0x6f, 0x77, 0x6e, 0x12, 0x12,
+	0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69,
+	0x7a, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x71, 0x75, 0x61, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x71, 0x75, 0x61, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x22, 0x83,
+	0x02, 0x0a, 0x11, 0x43, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x49, 0x64, 0x12,
+	0x26, 0x0a, 0x0f, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x5f,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x4f, 0x72, 0x64, 0x65, 0x72, 0x49, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x6b, 0x75, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x6b, 0x75, 0x12, 0x25, 0x0a, 0x0e, 0x6f, 0x72, 0x64,
+	0x65, 0x72, 0x5f, 0x71, 0x75, 0x61, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0d, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x51, 0x75, 0x61, 0x6e, 0x74, 0x69, 0x74, 0x79,
+	0x12, 0x30, 0x0a, 0x05, 0x70, 0x61, 0x63, 0x6b, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x70, 0x61, 0x63, 0x6b, 0x63, 0x61, 0x6c, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x61,
+	0x63, 0x6b, 0x42, 0x72, 0x65, 0x61, 0x6b, 0x64, 0x6f, 0x77, 0x6e, 0x52, 0x05, 0x70, 0x61, 0x63,
+	0x6b, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x69, 0x74, 0x65, 0x6d,
+	0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x49, 0x74,
+	0x65, 0x6d, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x70, 0x61, 0x63,
+	0x6b, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x50,
+	0x61, 0x63, 0x6b, 0x73, 0x22, 0x4b, 0x0a, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x12, 0x0a,
+	0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x6f, 0x64,
+	0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x66,
+	0x69, 0x65, 0x6c, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x66, 0x69, 0x65, 0x6c,
+	0x64, 0x22, 0xa2, 0x01, 0x0a, 0x16, 0x43, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x42,
+	0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05,
+	0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x12, 0x3c, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x70, 0x61, 0x63, 0x6b, 0x63, 0x61, 0x6c, 0x63, 0x2e,
+	0x76, 0x31, 0x2e, 0x43, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x2a, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x12, 0x2e, 0x70, 0x61, 0x63, 0x6b, 0x63, 0x61, 0x6c, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x72,
+	0x72, 0x6f, 0x72, 0x48, 0x00, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x42, 0x08, 0x0a, 0x06,
+	0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x32, 0xd0, 0x02, 0x0a, 0x0e, 0x50, 0x61, 0x63, 0x6b, 0x43,
+	0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x48, 0x0a, 0x0c, 0x47, 0x65, 0x74,
+	0x50, 0x61, 0x63, 0x6b, 0x53, 0x69, 0x7a, 0x65, 0x73, 0x12, 0x20, 0x2e, 0x70, 0x61, 0x63, 0x6b,
+	0x63, 0x61, 0x6c, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x61, 0x63, 0x6b, 0x53,
+	0x69, 0x7a, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x70, 0x61,
+	0x63, 0x6b, 0x63, 0x61, 0x6c, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x61, 0x63, 0x6b, 0x53, 0x69,
+	0x7a, 0x65, 0x73, 0x12, 0x4e, 0x0a, 0x0f, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x61, 0x63,
+	0x6b, 0x53, 0x69, 0x7a, 0x65, 0x73, 0x12, 0x23, 0x2e, 0x70, 0x61, 0x63, 0x6b, 0x63, 0x61, 0x6c,
+	0x63, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x61, 0x63, 0x6b, 0x53,
+	0x69, 0x7a, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x70, 0x61,
+	0x63, 0x6b, 0x63, 0x61, 0x6c, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x61, 0x63, 0x6b, 0x53, 0x69,
+	0x7a, 0x65, 0x73, 0x12, 0x4a, 0x0a, 0x09, 0x43, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x65,
+	0x12, 0x1d, 0x2e, 0x70, 0x61, 0x63, 0x6b, 0x63, 0x61, 0x6c, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x43,
+	0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1e, 0x2e, 0x70, 0x61, 0x63, 0x6b, 0x63, 0x61, 0x6c, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61,
+	0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x58, 0x0a, 0x0e, 0x43, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x12, 0x1d, 0x2e, 0x70, 0x61, 0x63, 0x6b, 0x63, 0x61, 0x6c, 0x63, 0x2e, 0x76, 0x31, 0x2e,
+	0x43, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x23, 0x2e, 0x70, 0x61, 0x63, 0x6b, 0x63, 0x61, 0x6c, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x43,
+	0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x42, 0x34, 0x5a, 0x32, 0x6f, 0x72, 0x64,
+	0x65, 0x72, 0x2d, 0x70, 0x61, 0x63, 0x6b, 0x2d, 0x63, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74,
+	0x6f, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x70, 0x61, 0x63, 0x6b, 0x63, 0x61, 0x6c,
+	0x63, 0x2f, 0x76, 0x31, 0x3b, 0x70, 0x61, 0x63, 0x6b, 0x63, 0x61, 0x6c, 0x63, 0x76, 0x31, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_packcalc_v1_packcalc_proto_rawDescOnce sync.Once
+	file_packcalc_v1_packcalc_proto_rawDescData []byte
+)
+
+func file_packcalc_v1_packcalc_proto_rawDescGZIP() []byte {
+	file_packcalc_v1_packcalc_proto_rawDescOnce.Do(func() {
+		file_packcalc_v1_packcalc_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_packcalc_v1_packcalc_proto_rawDesc), len(file_packcalc_v1_packcalc_proto_rawDesc)))
+	})
+	return file_packcalc_v1_packcalc_proto_rawDescData
+}
+
+var file_packcalc_v1_packcalc_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_packcalc_v1_packcalc_proto_goTypes = []any{
+	(*GetPackSizesRequest)(nil),    // 0: packcalc.v1.GetPackSizesRequest
+	(*UpdatePackSizesRequest)(nil), // 1: packcalc.v1.UpdatePackSizesRequest
+	(*PackSizes)(nil),              // 2: packcalc.v1.PackSizes
+	(*CalculateRequest)(nil),       // 3: packcalc.v1.CalculateRequest
+	(*PackBreakdown)(nil),          // 4: packcalc.v1.PackBreakdown
+	(*CalculateResponse)(nil),      // 5: packcalc.v1.CalculateResponse
+	(*Error)(nil),                  // 6: packcalc.v1.Error
+	(*CalculateBatchResponse)(nil), // 7: packcalc.v1.CalculateBatchResponse
+}
+var file_packcalc_v1_packcalc_proto_depIdxs = []int32{
+	4, // 0: packcalc.v1.CalculateResponse.packs:type_name -> packcalc.v1.PackBreakdown
+	5, // 1: packcalc.v1.CalculateBatchResponse.response:type_name -> packcalc.v1.CalculateResponse
+	6, // 2: packcalc.v1.CalculateBatchResponse.error:type_name -> packcalc.v1.Error
+	0, // 3: packcalc.v1.PackCalculator.GetPackSizes:input_type -> packcalc.v1.GetPackSizesRequest
+	1, // 4: packcalc.v1.PackCalculator.UpdatePackSizes:input_type -> packcalc.v1.UpdatePackSizesRequest
+	3, // 5: packcalc.v1.PackCalculator.Calculate:input_type -> packcalc.v1.CalculateRequest
+	3, // 6: packcalc.v1.PackCalculator.CalculateBatch:input_type -> packcalc.v1.CalculateRequest
+	2, // 7: packcalc.v1.PackCalculator.GetPackSizes:output_type -> packcalc.v1.PackSizes
+	2, // 8: packcalc.v1.PackCalculator.UpdatePackSizes:output_type -> packcalc.v1.PackSizes
+	5, // 9: packcalc.v1.PackCalculator.Calculate:output_type -> packcalc.v1.CalculateResponse
+	7, // 10: packcalc.v1.PackCalculator.CalculateBatch:output_type -> packcalc.v1.CalculateBatchResponse
+	7, // [7:11] is the sub-list for method output_type
+	3, // [3:7] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_packcalc_v1_packcalc_proto_init() }
+func file_packcalc_v1_packcalc_proto_init() {
+	if File_packcalc_v1_packcalc_proto != nil {
+		return
+	}
+	file_packcalc_v1_packcalc_proto_msgTypes[3].OneofWrappers = []any{}
+	file_packcalc_v1_packcalc_proto_msgTypes[7].OneofWrappers = []any{
+		(*CalculateBatchResponse_Response)(nil),
+		(*CalculateBatchResponse_Error)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_packcalc_v1_packcalc_proto_rawDesc), len(file_packcalc_v1_packcalc_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_packcalc_v1_packcalc_proto_goTypes,
+		DependencyIndexes: file_packcalc_v1_packcalc_proto_depIdxs,
+		MessageInfos:      file_packcalc_v1_packcalc_proto_msgTypes,
+	}.Build()
+	File_packcalc_v1_packcalc_proto = out.File
+	file_packcalc_v1_packcalc_proto_goTypes = nil
+	file_packcalc_v1_packcalc_proto_depIdxs = nil
+}
@@ -0,0 +1,252 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: packcalc/v1/packcalc.proto
+
+package packcalcv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PackCalculator_GetPackSizes_FullMethodName    = "/packcalc.v1.PackCalculator/GetPackSizes"
+	PackCalculator_UpdatePackSizes_FullMethodName = "/packcalc.v1.PackCalculator/UpdatePackSizes"
+	PackCalculator_Calculate_FullMethodName       = "/packcalc.v1.PackCalculator/Calculate"
+	PackCalculator_CalculateBatch_FullMethodName  = "/packcalc.v1.PackCalculator/CalculateBatch"
+)
+
+// PackCalculatorClient is the client API for PackCalculator service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PackCalculator calculates which whole packs to ship for orders.
+//
+// Errors carry a google.rpc.ErrorInfo detail whose reason is the same error
+// code as in the HTTP API's problem responses, e.g. "invalid_pack_sizes".
+type PackCalculatorClient interface {
+	// GetPackSizes returns the current pack sizes.
+	GetPackSizes(ctx context.Context, in *GetPackSizesRequest, opts ...grpc.CallOption) (*PackSizes, error)
+	// UpdatePackSizes replaces the pack sizes.
+	UpdatePackSizes(ctx context.Context, in *UpdatePackSizesRequest, opts ...grpc.CallOption) (*PackSizes, error)
+	// Calculate calculates the packs for an order.
+	Calculate(ctx context.Context, in *CalculateRequest, opts ...grpc.CallOption) (*CalculateResponse, error)
+	// CalculateBatch calculates the packs for a stream of orders, answering
+	// each one in order. Invalid orders are answered with an error result
+	// without ending the stream.
+	CalculateBatch(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[CalculateRequest, CalculateBatchResponse], error)
+}
+
+type packCalculatorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPackCalculatorClient(cc grpc.ClientConnInterface) PackCalculatorClient {
+	return &packCalculatorClient{cc}
+}
+
+func (c *packCalculatorClient) GetPackSizes(ctx context.Context, in *GetPackSizesRequest, opts ...grpc.CallOption) (*PackSizes, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PackSizes)
+	err := c.cc.Invoke(ctx, PackCalculator_GetPackSizes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *packCalculatorClient) UpdatePackSizes(ctx context.Context, in *UpdatePackSizesRequest, opts ...grpc.CallOption) (*PackSizes, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PackSizes)
+	err := c.cc.Invoke(ctx, PackCalculator_UpdatePackSizes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *packCalculatorClient) Calculate(ctx context.Context, in *CalculateRequest, opts ...grpc.CallOption) (*CalculateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CalculateResponse)
+	err := c.cc.Invoke(ctx, PackCalculator_Calculate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *packCalculatorClient) CalculateBatch(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[CalculateRequest, CalculateBatchResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PackCalculator_ServiceDesc.Streams[0], PackCalculator_CalculateBatch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[CalculateRequest, CalculateBatchResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PackCalculator_CalculateBatchClient = grpc.BidiStreamingClient[CalculateRequest, CalculateBatchResponse]
+
+// PackCalculatorServer is the server API for PackCalculator service.
+// All implementations must embed UnimplementedPackCalculatorServer
+// for forward compatibility.
+//
+// PackCalculator calculates which whole packs to ship for orders.
+//
+// Errors carry a google.rpc.ErrorInfo detail whose reason is the same error
+// code as in the HTTP API's problem responses, e.g. "invalid_pack_sizes".
+type PackCalculatorServer interface {
+	// GetPackSizes returns the current pack sizes.
+	GetPackSizes(context.Context, *GetPackSizesRequest) (*PackSizes, error)
+	// UpdatePackSizes replaces the pack sizes.
+	UpdatePackSizes(context.Context, *UpdatePackSizesRequest) (*PackSizes, error)
+	// Calculate calculates the packs for an order.
+	Calculate(context.Context, *CalculateRequest) (*CalculateResponse, error)
+	// CalculateBatch calculates the packs for a stream of orders, answering
+	// each one in order. Invalid orders are answered with an error result
+	// without ending the stream.
+	CalculateBatch(grpc.BidiStreamingServer[CalculateRequest, CalculateBatchResponse]) error
+	mustEmbedUnimplementedPackCalculatorServer()
+}
+
+// UnimplementedPackCalculatorServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPackCalculatorServer struct{}
+
+func (UnimplementedPackCalculatorServer) GetPackSizes(context.Context, *GetPackSizesRequest) (*PackSizes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPackSizes not implemented")
+}
+func (UnimplementedPackCalculatorServer) UpdatePackSizes(context.Context, *UpdatePackSizesRequest) (*PackSizes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdatePackSizes not implemented")
+}
+func (UnimplementedPackCalculatorServer) Calculate(context.Context, *CalculateRequest) (*CalculateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Calculate not implemented")
+}
+func (UnimplementedPackCalculatorServer) CalculateBatch(grpc.BidiStreamingServer[CalculateRequest, CalculateBatchResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method CalculateBatch not implemented")
+}
+func (UnimplementedPackCalculatorServer) mustEmbedUnimplementedPackCalculatorServer() {}
+func (UnimplementedPackCalculatorServer) testEmbeddedByValue()                        {}
+
+// UnsafePackCalculatorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PackCalculatorServer will
+// result in compilation errors.
+type UnsafePackCalculatorServer interface {
+	mustEmbedUnimplementedPackCalculatorServer()
+}
+
+func RegisterPackCalculatorServer(s grpc.ServiceRegistrar, srv PackCalculatorServer) {
+	// If the following call pancis, it indicates UnimplementedPackCalculatorServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PackCalculator_ServiceDesc, srv)
+}
+
+func _PackCalculator_GetPackSizes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPackSizesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackCalculatorServer).GetPackSizes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackCalculator_GetPackSizes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackCalculatorServer).GetPackSizes(ctx, req.(*GetPackSizesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PackCalculator_UpdatePackSizes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePackSizesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackCalculatorServer).UpdatePackSizes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackCalculator_UpdatePackSizes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackCalculatorServer).UpdatePackSizes(ctx, req.(*UpdatePackSizesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PackCalculator_Calculate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CalculateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackCalculatorServer).Calculate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackCalculator_Calculate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackCalculatorServer).Calculate(ctx, req.(*CalculateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PackCalculator_CalculateBatch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PackCalculatorServer).CalculateBatch(&grpc.GenericServerStream[CalculateRequest, CalculateBatchResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PackCalculator_CalculateBatchServer = grpc.BidiStreamingServer[CalculateRequest, CalculateBatchResponse]
+
+// PackCalculator_ServiceDesc is the grpc.ServiceDesc for PackCalculator service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PackCalculator_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "packcalc.v1.PackCalculator",
+	HandlerType: (*PackCalculatorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPackSizes",
+			Handler:    _PackCalculator_GetPackSizes_Handler,
+		},
+		{
+			MethodName: "UpdatePackSizes",
+			Handler:    _PackCalculator_UpdatePackSizes_Handler,
+		},
+		{
+			MethodName: "Calculate",
+			Handler:    _PackCalculator_Calculate_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CalculateBatch",
+			Handler:       _PackCalculator_CalculateBatch_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "packcalc/v1/packcalc.proto",
+}
@@ -3,10 +3,11 @@ const API_BASE = window.location.origin;
 
 // State
 let currentPackSizes = [];
+let apiKey = localStorage.getItem('apiKey') || '';
 
 // Initialize app
 document.addEventListener('DOMContentLoaded', () => {
-    loadPackSizes();
+    subscribePackSizes();
 
     document.getElementById('addPackBtn').addEventListener('click', addPackSizeInput);
     document.getElementById('submitPacksBtn').addEventListener('click', updatePackSizes);
@@ -20,10 +21,32 @@ document.addEventListener('DOMContentLoaded', () => {
     });
 });
 
+// Call the API with the stored API key, asking for a key when the server
+// requires one the stored key does not grant
+async function apiFetch(path, options = {}) {
+    const headers = { ...options.headers };
+    if (apiKey) {
+        headers['X-API-Key'] = apiKey;
+    }
+
+    const response = await fetch(`${API_BASE}${path}`, { ...options, headers });
+    if (response.status === 401 || response.status === 403) {
+        const key = prompt(response.status === 401
+            ? 'This server requires an API key:'
+            : 'This API key may not do that. Enter an admin API key:');
+        if (key) {
+            apiKey = key.trim();
+            localStorage.setItem('apiKey', apiKey);
+            window.location.reload();
+        }
+    }
+    return response;
+}
+
 // Load current pack sizes from API
 async function loadPackSizes() {
     try {
-        const response = await fetch(`${API_BASE}/api/packs`);
+        const response = await apiFetch('/api/v1/packs');
         const data = await response.json();
 
         if (data.pack_sizes) {
@@ -36,6 +59,35 @@ async function loadPackSizes() {
     }
 }
 
+// Follow pack size changes made by anyone, falling back to a single load
+// in browsers without Server-Sent Events
+function subscribePackSizes() {
+    if (!window.EventSource) {
+        loadPackSizes();
+        return;
+    }
+
+    // The browser reconnects on its own, resuming from the last event ID.
+    // EventSource cannot send headers, so the key goes in the query.
+    const query = apiKey ? `?api_key=${encodeURIComponent(apiKey)}` : '';
+    const events = new EventSource(`${API_BASE}/api/v1/packs/events${query}`);
+    events.addEventListener('pack_sizes', (event) => {
+        const data = JSON.parse(event.data);
+        if (data.pack_sizes) {
+            currentPackSizes = data.pack_sizes;
+            renderPackSizes();
+        }
+    });
+
+    // The browser gives up on errors such as a rejected key; loading once
+    // reports them
+    events.addEventListener('error', () => {
+        if (events.readyState === EventSource.CLOSED) {
+            loadPackSizes();
+        }
+    });
+}
+
 // Get current values from input fields (preserves unsaved changes)
 function getCurrentInputValues() {
     const inputs = document.querySelectorAll('#packSizesContainer input');
@@ -180,7 +232,7 @@ async function updatePackSizes() {
     }
 
     try {
-        const response = await fetch(`${API_BASE}/api/packs`, {
+        const response = await apiFetch('/api/v1/packs', {
             method: 'PUT',
             headers: {
                 'Content-Type': 'application/json',
@@ -215,7 +267,7 @@ async function calculatePacks() {
     hideError();
 
     try {
-        const response = await fetch(`${API_BASE}/api/calculate`, {
+        const response = await apiFetch('/api/v1/calculate', {
             method: 'POST',
             headers: {
                 'Content-Type': 'application/json',